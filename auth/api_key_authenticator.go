@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"github.com/anhsbolic/closure-table-go/config"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/anhsbolic/closure-table-go/repository"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+const bootstrapApiKeySubject = "bootstrap"
+
+// ApiKeyAuthenticator checks the X-API-Key header against the api_keys table. X_API_KEY, when set,
+// is accepted as a bootstrap admin key
+type ApiKeyAuthenticator struct {
+	ApiKeyRepository repository.ApiKeyRepository
+	DB               *sql.DB
+	BootstrapKey     []byte
+}
+
+func NewApiKeyAuthenticator(db *sql.DB) Authenticator {
+	// Get Config
+	env := config.GetEnvConfig()
+
+	return &ApiKeyAuthenticator{
+		ApiKeyRepository: repository.NewApiKeyRepository(),
+		DB:               db,
+		BootstrapKey:     []byte(env.GetString("X_API_KEY")),
+	}
+}
+
+func (authenticator *ApiKeyAuthenticator) Authenticate(ctx *fiber.Ctx) (pkg.Principal, error) {
+	// Get Header
+	key := ctx.Get("X-API-Key")
+	if key == "" {
+		return pkg.Principal{}, ErrNoCredentials
+	}
+
+	// Check Bootstrap Key
+	if len(authenticator.BootstrapKey) > 0 && subtle.ConstantTimeCompare([]byte(key), authenticator.BootstrapKey) == 1 {
+		return pkg.Principal{
+			Type:    pkg.PrincipalTypeApiKey,
+			Subject: bootstrapApiKeySubject,
+			Name:    bootstrapApiKeySubject,
+			Scopes:  []string{pkg.ScopeAdmin},
+		}, nil
+	}
+
+	// Check Api Key By ID
+	apiKeyId, ok := pkg.ParseApiKeyID(key)
+	if !ok {
+		return pkg.Principal{}, ErrInvalidCredentials
+	}
+	apiKey, err := authenticator.ApiKeyRepository.DetailByID(ctx.UserContext(), authenticator.DB, apiKeyId.String())
+	if err != nil {
+		return pkg.Principal{}, err
+	}
+	if !isApiKeyUsable(apiKey, key, time.Now()) {
+		return pkg.Principal{}, ErrInvalidCredentials
+	}
+
+	return pkg.Principal{
+		Type:    pkg.PrincipalTypeApiKey,
+		Subject: apiKey.ID.String(),
+		Name:    apiKey.Name,
+		Scopes:  apiKey.Scopes,
+	}, nil
+}
+
+// isApiKeyUsable checks the key hash before expiry and revocation so unknown and disabled keys take
+// the same path
+func isApiKeyUsable(apiKey domain.ApiKey, key string, now time.Time) bool {
+	if !pkg.VerifyApiKeyHash(key, apiKey.KeyHash) {
+		return false
+	}
+	if apiKey.RevokedAt.Valid {
+		return false
+	}
+	if apiKey.ExpiresAt.Valid && !apiKey.ExpiresAt.Time.After(now) {
+		return false
+	}
+
+	return true
+}
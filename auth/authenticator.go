@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	// ErrNoCredentials means the request has no credentials for this authenticator, the next one is tried
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials means the request has credentials for this authenticator but they were rejected
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Authenticator resolves the principal of a request from one kind of credentials
+type Authenticator interface {
+	Authenticate(ctx *fiber.Ctx) (pkg.Principal, error)
+}
+
+// NewAuthenticators returns the configured authenticator chain, API keys are always accepted and
+// bearer JWTs when JWT_HS256_SECRETS or JWT_JWKS_FILE is set
+func NewAuthenticators(db *sql.DB) []Authenticator {
+	authenticators := []Authenticator{NewApiKeyAuthenticator(db)}
+
+	jwtAuthenticator := NewJWTAuthenticator()
+	if jwtAuthenticator != nil {
+		authenticators = append(authenticators, jwtAuthenticator)
+	}
+
+	return authenticators
+}
@@ -0,0 +1,80 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// RSAPublicKey is a signing key from a JWKS file, ID is the kid tokens refer to
+type RSAPublicKey struct {
+	ID  string
+	Key *rsa.PublicKey
+}
+
+// LoadJWKS reads the RS256 signing keys of a JWKS file, other keys are skipped
+func LoadJWKS(path string) ([]RSAPublicKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keySet jsonWebKeySet
+	err = json.Unmarshal(content, &keySet)
+	if err != nil {
+		return nil, fmt.Errorf("jwks %s: %w", path, err)
+	}
+
+	var keys []RSAPublicKey
+	for i, jwk := range keySet.Keys {
+		if jwk.Kty != "RSA" || jwk.Use == "enc" || (jwk.Alg != "" && jwk.Alg != "RS256") {
+			continue
+		}
+
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("jwks %s: key %d: %w", path, i, err)
+		}
+		keys = append(keys, RSAPublicKey{ID: jwk.Kid, Key: key})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks %s: no RS256 signing keys", path)
+	}
+
+	return keys, nil
+}
+
+func (jwk jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA public key")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"fmt"
+	"github.com/anhsbolic/closure-table-go/config"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"strings"
+	"time"
+)
+
+const (
+	defaultJWTLeeway      = 30 * time.Second
+	defaultJWTTenantClaim = "tenant"
+	defaultJWTRolesClaim  = "roles"
+)
+
+// JWTAuthenticator checks Authorization: Bearer tokens signed with one of the HS256 secrets or an
+// RS256 key from the JWKS file, and maps their claims to a principal
+type JWTAuthenticator struct {
+	Secrets     [][]byte
+	RSAKeys     []RSAPublicKey
+	Parser      *jwt.Parser
+	TenantClaim string
+	RolesClaim  string
+}
+
+// NewJWTAuthenticator returns nil when neither JWT_HS256_SECRETS nor JWT_JWKS_FILE is set
+func NewJWTAuthenticator() Authenticator {
+	// Get Config
+	env := config.GetEnvConfig()
+	var secrets [][]byte
+	for _, secret := range strings.Split(env.GetString("JWT_HS256_SECRETS"), ",") {
+		secret = strings.TrimSpace(secret)
+		if secret != "" {
+			secrets = append(secrets, []byte(secret))
+		}
+	}
+	var rsaKeys []RSAPublicKey
+	if jwksFile := env.GetString("JWT_JWKS_FILE"); jwksFile != "" {
+		keys, err := LoadJWKS(jwksFile)
+		pkg.PanicIfError(err)
+		rsaKeys = keys
+	}
+	if len(secrets) == 0 && len(rsaKeys) == 0 {
+		return nil
+	}
+
+	// Setup Parser, only the configured algorithms are accepted
+	var methods []string
+	if len(secrets) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if len(rsaKeys) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	leeway := env.GetDuration("JWT_LEEWAY")
+	if leeway <= 0 {
+		leeway = defaultJWTLeeway
+	}
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(methods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+	}
+	if issuer := env.GetString("JWT_ISSUER"); issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience := env.GetString("JWT_AUDIENCE"); audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+
+	tenantClaim := env.GetString("JWT_TENANT_CLAIM")
+	if tenantClaim == "" {
+		tenantClaim = defaultJWTTenantClaim
+	}
+	rolesClaim := env.GetString("JWT_ROLES_CLAIM")
+	if rolesClaim == "" {
+		rolesClaim = defaultJWTRolesClaim
+	}
+
+	return &JWTAuthenticator{
+		Secrets:     secrets,
+		RSAKeys:     rsaKeys,
+		Parser:      jwt.NewParser(options...),
+		TenantClaim: tenantClaim,
+		RolesClaim:  rolesClaim,
+	}
+}
+
+func (authenticator *JWTAuthenticator) Authenticate(ctx *fiber.Ctx) (pkg.Principal, error) {
+	// Get Header
+	scheme, tokenString, found := strings.Cut(ctx.Get(fiber.HeaderAuthorization), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return pkg.Principal{}, ErrNoCredentials
+	}
+
+	// Verify Token
+	claims := jwt.MapClaims{}
+	_, err := authenticator.Parser.ParseWithClaims(strings.TrimSpace(tokenString), claims, authenticator.keyFunc)
+	if err != nil {
+		return pkg.Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return pkg.Principal{}, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+
+	// Map Claims
+	name, _ := claims["name"].(string)
+	tenant, _ := claims[authenticator.TenantClaim].(string)
+	scopes := stringsClaim(claims["scope"])
+	if len(scopes) == 0 {
+		scopes = stringsClaim(claims["scp"])
+	}
+
+	return pkg.Principal{
+		Type:    pkg.PrincipalTypeJWT,
+		Subject: subject,
+		Name:    name,
+		Tenant:  tenant,
+		Roles:   stringsClaim(claims[authenticator.RolesClaim]),
+		Scopes:  scopes,
+	}, nil
+}
+
+// keyFunc returns the keys matching the token algorithm, RS256 tokens with a kid only try that key
+func (authenticator *JWTAuthenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	keySet := jwt.VerificationKeySet{}
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		for _, secret := range authenticator.Secrets {
+			keySet.Keys = append(keySet.Keys, secret)
+		}
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		for _, rsaKey := range authenticator.RSAKeys {
+			if kid == "" || rsaKey.ID == kid {
+				keySet.Keys = append(keySet.Keys, rsaKey.Key)
+			}
+		}
+	}
+	if len(keySet.Keys) == 0 {
+		return nil, fmt.Errorf("no key for %s token", token.Method.Alg())
+	}
+
+	return keySet, nil
+}
+
+// stringsClaim reads a claim holding either an array of strings or a space-separated string
+func stringsClaim(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, item := range value {
+			if text, ok := item.(string); ok && text != "" {
+				values = append(values, text)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/anhsbolic/closure-table-go/repository"
+	"github.com/anhsbolic/closure-table-go/service"
+	"os"
+	"time"
+)
+
+// runCommand runs a maintenance subcommand instead of the HTTP server and returns the exit code
+func runCommand(name string) int {
+	// Setup DB
+	db := pkg.NewDB()
+	defer db.Close()
+
+	// Setup Closure Service
+	nodeRepository := repository.NewNodeRepository()
+	nodeClosureRepository := repository.NewNodeClosureRepository()
+	closureService := service.NewClosureService(nodeRepository, nodeClosureRepository, db)
+
+	var result interface{}
+	exitCode := 0
+	switch name {
+	case "closure:verify":
+		verifyResult, err := closureService.Verify(context.Background())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if !verifyResult.Valid {
+			exitCode = 1
+		}
+		result = verifyResult
+	case "closure:rebuild":
+		rebuildResult, err := closureService.Rebuild(context.Background())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		result = rebuildResult
+	case "idempotency:purge":
+		idempotencyKeyRepository := repository.NewIdempotencyKeyRepository()
+		purged, err := idempotencyKeyRepository.DeleteExpired(context.Background(), db, time.Now())
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		result = map[string]int64{"purged": purged}
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q, available commands: closure:verify, closure:rebuild, idempotency:purge\n", name)
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(result)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return exitCode
+}
@@ -0,0 +1,12 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+type ApiKeyController interface {
+	Create(ctx *fiber.Ctx) error
+	List(ctx *fiber.Ctx) error
+	Rotate(ctx *fiber.Ctx) error
+	Revoke(ctx *fiber.Ctx) error
+}
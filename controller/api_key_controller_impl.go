@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"github.com/anhsbolic/closure-table-go/model/dto"
+	"github.com/anhsbolic/closure-table-go/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ApiKeyControllerImpl struct {
+	ApiKeyService service.ApiKeyService
+}
+
+func NewApiKeyController(apiKeyService service.ApiKeyService) ApiKeyController {
+	return &ApiKeyControllerImpl{
+		ApiKeyService: apiKeyService,
+	}
+}
+
+func (controller *ApiKeyControllerImpl) Create(ctx *fiber.Ctx) error {
+	request := new(dto.ApiKeyCreateRequest)
+	err := ctx.BodyParser(request)
+	if err != nil {
+		return err
+	}
+
+	result, err := controller.ApiKeyService.Create(ctx.UserContext(), *request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "API key has been created, store the key now as it cannot be shown again",
+		Data:    result,
+	})
+}
+
+func (controller *ApiKeyControllerImpl) List(ctx *fiber.Ctx) error {
+	result, err := controller.ApiKeyService.List(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "List of API keys",
+		Data:    result,
+	})
+}
+
+func (controller *ApiKeyControllerImpl) Rotate(ctx *fiber.Ctx) error {
+	apiKeyId := ctx.Params("apiKeyId")
+	result, err := controller.ApiKeyService.Rotate(ctx.UserContext(), apiKeyId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "API key has been rotated, store the key now as it cannot be shown again",
+		Data:    result,
+	})
+}
+
+func (controller *ApiKeyControllerImpl) Revoke(ctx *fiber.Ctx) error {
+	apiKeyId := ctx.Params("apiKeyId")
+	result, err := controller.ApiKeyService.Revoke(ctx.UserContext(), apiKeyId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "API key has been revoked",
+		Data:    result,
+	})
+}
@@ -0,0 +1,10 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+type ClosureController interface {
+	Verify(ctx *fiber.Ctx) error
+	Rebuild(ctx *fiber.Ctx) error
+}
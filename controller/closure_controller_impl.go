@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"github.com/anhsbolic/closure-table-go/model/dto"
+	"github.com/anhsbolic/closure-table-go/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ClosureControllerImpl struct {
+	ClosureService service.ClosureService
+}
+
+func NewClosureController(closureService service.ClosureService) ClosureController {
+	return &ClosureControllerImpl{
+		ClosureService: closureService,
+	}
+}
+
+func (controller *ClosureControllerImpl) Verify(ctx *fiber.Ctx) error {
+	result, err := controller.ClosureService.Verify(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	message := "Closure table is consistent"
+	if !result.Valid {
+		message = "Closure table has integrity issues"
+	}
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: message,
+		Data:    result,
+	})
+}
+
+func (controller *ClosureControllerImpl) Rebuild(ctx *fiber.Ctx) error {
+	result, err := controller.ClosureService.Rebuild(ctx.UserContext())
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Closure table has been rebuilt",
+		Data:    result,
+	})
+}
@@ -8,8 +8,25 @@ type NodeController interface {
 	Create(ctx *fiber.Ctx) error
 	RootList(ctx *fiber.Ctx) error
 	DetailNode(ctx *fiber.Ctx) error
+	DetailByExternalID(ctx *fiber.Ctx) error
+	UpsertByExternalID(ctx *fiber.Ctx) error
 	UpdateNode(ctx *fiber.Ctx) error
 	DeleteNode(ctx *fiber.Ctx) error
 	DescendantList(ctx *fiber.Ctx) error
 	MoveNode(ctx *fiber.Ctx) error
+	ImportNodes(ctx *fiber.Ctx) error
+	ImportOutline(ctx *fiber.Ctx) error
+	ImportCSV(ctx *fiber.Ctx) error
+	ExportNode(ctx *fiber.Ctx) error
+	CopyNode(ctx *fiber.Ctx) error
+	ReorderNode(ctx *fiber.Ctx) error
+	ChildList(ctx *fiber.Ctx) error
+	TrashList(ctx *fiber.Ctx) error
+	RestoreNode(ctx *fiber.Ctx) error
+	PurgeNode(ctx *fiber.Ctx) error
+	TreeNode(ctx *fiber.Ctx) error
+	AncestorList(ctx *fiber.Ctx) error
+	AncestorListBulk(ctx *fiber.Ctx) error
+	LowestCommonAncestor(ctx *fiber.Ctx) error
+	PathTo(ctx *fiber.Ctx) error
 }
@@ -1,11 +1,40 @@
 package controller
 
 import (
+	"bufio"
+	"bytes"
 	"github.com/anhsbolic/closure-table-go/model/dto"
+	"github.com/anhsbolic/closure-table-go/pkg"
 	"github.com/anhsbolic/closure-table-go/service"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"net/url"
+	"strings"
 )
 
+var exportContentTypes = map[string]string{
+	dto.NodeExportFormatJSON:     fiber.MIMEApplicationJSONCharsetUTF8,
+	dto.NodeExportFormatNDJSON:   "application/x-ndjson; charset=utf-8",
+	dto.NodeExportFormatCSV:      "text/csv; charset=utf-8",
+	dto.NodeExportFormatOPML:     "text/x-opml; charset=utf-8",
+	dto.NodeExportFormatMarkdown: "text/markdown; charset=utf-8",
+}
+
+// exportStatusTrailer is sent after the exported rows, complete only when every row was written
+const (
+	exportStatusTrailer  = "X-Export-Status"
+	exportStatusComplete = "complete"
+	exportStatusFailed   = "failed"
+)
+
+var exportExtensions = map[string]string{
+	dto.NodeExportFormatJSON:     "json",
+	dto.NodeExportFormatNDJSON:   "ndjson",
+	dto.NodeExportFormatCSV:      "csv",
+	dto.NodeExportFormatOPML:     "opml",
+	dto.NodeExportFormatMarkdown: "md",
+}
+
 type NodeControllerImpl struct {
 	NodeService service.NodeService
 }
@@ -62,6 +91,54 @@ func (controller *NodeControllerImpl) DetailNode(ctx *fiber.Ctx) error {
 	})
 }
 
+func (controller *NodeControllerImpl) DetailByExternalID(ctx *fiber.Ctx) error {
+	externalId, err := url.PathUnescape(ctx.Params("externalId"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	result, err := controller.NodeService.DetailByExternalID(ctx.UserContext(), externalId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Detail of node",
+		Data:    result,
+	})
+}
+
+func (controller *NodeControllerImpl) UpsertByExternalID(ctx *fiber.Ctx) error {
+	externalId, err := url.PathUnescape(ctx.Params("externalId"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request := new(dto.NodeUpsertRequest)
+	err = ctx.BodyParser(request)
+	if err != nil {
+		return err
+	}
+
+	result, isCreated, err := controller.NodeService.UpsertByExternalID(ctx.UserContext(), externalId, *request)
+	if err != nil {
+		return err
+	}
+
+	if isCreated {
+		return ctx.Status(fiber.StatusCreated).JSON(dto.ApiResponseSuccess{
+			Success: true,
+			Message: "Node has been created",
+			Data:    result,
+		})
+	}
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Node detail has been updated",
+		Data:    result,
+	})
+}
+
 func (controller *NodeControllerImpl) UpdateNode(ctx *fiber.Ctx) error {
 	nodeId := ctx.Params("nodeId")
 	request := new(dto.NodeUpdateRequest)
@@ -84,14 +161,24 @@ func (controller *NodeControllerImpl) UpdateNode(ctx *fiber.Ctx) error {
 
 func (controller *NodeControllerImpl) DeleteNode(ctx *fiber.Ctx) error {
 	nodeId := ctx.Params("nodeId")
-	err := controller.NodeService.DeleteNode(ctx.UserContext(), nodeId)
+	request := new(dto.NodeDeleteRequest)
+	err := ctx.QueryParser(request)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	err = controller.NodeService.DeleteNode(ctx.UserContext(), nodeId, *request)
 	if err != nil {
 		return err
 	}
 
+	message := "Node with all descendants has been moved to trash"
+	if request.Mode == dto.NodeDeleteModePromote {
+		message = "Node has been moved to trash and its children have been promoted"
+	}
 	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
 		Success: true,
-		Message: "Node with all descendants has been deleted",
+		Message: message,
 	})
 }
 
@@ -127,3 +214,310 @@ func (controller *NodeControllerImpl) MoveNode(ctx *fiber.Ctx) error {
 		Message: "Node has been moved",
 	})
 }
+
+func (controller *NodeControllerImpl) ImportNodes(ctx *fiber.Ctx) error {
+	request := new(dto.NodeImportRequest)
+	err := ctx.BodyParser(request)
+	if err != nil {
+		return err
+	}
+
+	result, err := controller.NodeService.ImportNodes(ctx.UserContext(), *request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Nodes have been imported",
+		Data:    result,
+	})
+}
+
+func (controller *NodeControllerImpl) ImportOutline(ctx *fiber.Ctx) error {
+	request := new(dto.NodeOutlineImportRequest)
+	err := ctx.ParamsParser(request)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	err = ctx.QueryParser(request)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	result, err := controller.NodeService.ImportOutline(ctx.UserContext(), *request, bytes.NewReader(ctx.Body()))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Outline has been imported",
+		Data:    result,
+	})
+}
+
+func (controller *NodeControllerImpl) ImportCSV(ctx *fiber.Ctx) error {
+	request := new(dto.NodeCSVImportRequest)
+	err := ctx.QueryParser(request)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	result, err := controller.NodeService.ImportCSV(ctx.UserContext(), *request, bytes.NewReader(ctx.Body()))
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "CSV rows have been imported",
+		Data:    result,
+	})
+}
+
+func (controller *NodeControllerImpl) ExportNode(ctx *fiber.Ctx) error {
+	// Copy params, the export is streamed after the handler returns
+	nodeId := utils.CopyString(ctx.Params("nodeId"))
+	request := new(dto.NodeExportRequest)
+	err := ctx.QueryParser(request)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	request.Format = utils.CopyString(request.Format)
+	if request.Format == "" {
+		request.Format = dto.NodeExportFormatJSON
+	}
+
+	writeExport, err := controller.NodeService.ExportNode(ctx.UserContext(), nodeId, *request)
+	if err != nil {
+		return err
+	}
+
+	ctx.Attachment(nodeId + "." + exportExtensions[request.Format])
+	ctx.Set(fiber.HeaderContentType, exportContentTypes[request.Format])
+
+	// Announce the status trailer, the status code is sent before the first row so only the
+	// trailer tells a complete export from one cut off by an error
+	header := &ctx.Context().Response.Header
+	err = header.SetTrailer(exportStatusTrailer)
+	if err != nil {
+		return err
+	}
+	logger := pkg.NewLogger()
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		status := exportStatusComplete
+		err := writeExport(w)
+		if err != nil {
+			logger.Error(err)
+			status = exportStatusFailed
+		}
+		header.Set(exportStatusTrailer, status)
+		err = w.Flush()
+		if err != nil {
+			logger.Error(err)
+		}
+	})
+
+	return nil
+}
+
+func (controller *NodeControllerImpl) CopyNode(ctx *fiber.Ctx) error {
+	nodeId := ctx.Params("nodeId")
+	request := new(dto.NodeCopyRequest)
+	err := ctx.BodyParser(request)
+	if err != nil {
+		return err
+	}
+
+	result, err := controller.NodeService.CopyNode(ctx.UserContext(), nodeId, *request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Node with all descendants has been copied",
+		Data:    result,
+	})
+}
+
+func (controller *NodeControllerImpl) ReorderNode(ctx *fiber.Ctx) error {
+	nodeId := ctx.Params("nodeId")
+	request := new(dto.NodePositionRequest)
+	err := ctx.BodyParser(request)
+	if err != nil {
+		return err
+	}
+
+	err = controller.NodeService.ReorderNode(ctx.UserContext(), nodeId, *request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Node has been reordered",
+	})
+}
+
+func (controller *NodeControllerImpl) ChildList(ctx *fiber.Ctx) error {
+	nodeId := ctx.Params("nodeId")
+	request := new(dto.PaginationRequest)
+	err := ctx.QueryParser(request)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	result, meta, err := controller.NodeService.ChildList(ctx.UserContext(), nodeId, *request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponsePagination{
+		Success: true,
+		Message: "List of child nodes",
+		Data:    result,
+		Meta:    meta,
+	})
+}
+
+func (controller *NodeControllerImpl) AncestorList(ctx *fiber.Ctx) error {
+	nodeId := ctx.Params("nodeId")
+	result, err := controller.NodeService.AncestorList(ctx.UserContext(), nodeId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Path of ancestor nodes",
+		Data:    result,
+	})
+}
+
+func (controller *NodeControllerImpl) AncestorListBulk(ctx *fiber.Ctx) error {
+	request := new(dto.NodeAncestorBulkRequest)
+	err := ctx.BodyParser(request)
+	if err != nil {
+		return err
+	}
+
+	result, err := controller.NodeService.AncestorListBulk(ctx.UserContext(), *request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Paths of ancestor nodes",
+		Data:    result,
+	})
+}
+
+func (controller *NodeControllerImpl) LowestCommonAncestor(ctx *fiber.Ctx) error {
+	request := new(dto.NodeLCARequest)
+	err := ctx.QueryParser(request)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Accept both ids=a,b and ids=a&ids=b
+	var ids []string
+	for _, value := range request.IDs {
+		ids = append(ids, strings.Split(value, ",")...)
+	}
+	request.IDs = ids
+
+	result, err := controller.NodeService.LowestCommonAncestor(ctx.UserContext(), *request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Lowest common ancestor of nodes",
+		Data:    result,
+	})
+}
+
+func (controller *NodeControllerImpl) PathTo(ctx *fiber.Ctx) error {
+	nodeId := ctx.Params("nodeId")
+	targetId := ctx.Params("targetId")
+	result, err := controller.NodeService.PathTo(ctx.UserContext(), nodeId, targetId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Path between nodes",
+		Data:    result,
+	})
+}
+
+func (controller *NodeControllerImpl) TreeNode(ctx *fiber.Ctx) error {
+	nodeId := ctx.Params("nodeId")
+	request := new(dto.NodeTreeRequest)
+	err := ctx.QueryParser(request)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	result, err := controller.NodeService.TreeNode(ctx.UserContext(), nodeId, *request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Tree of node",
+		Data:    result,
+	})
+}
+
+func (controller *NodeControllerImpl) TrashList(ctx *fiber.Ctx) error {
+	request := new(dto.PaginationRequest)
+	err := ctx.QueryParser(request)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	result, meta, err := controller.NodeService.TrashList(ctx.UserContext(), *request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponsePagination{
+		Success: true,
+		Message: "List of trashed nodes",
+		Data:    result,
+		Meta:    meta,
+	})
+}
+
+func (controller *NodeControllerImpl) RestoreNode(ctx *fiber.Ctx) error {
+	nodeId := ctx.Params("nodeId")
+	err := controller.NodeService.RestoreNode(ctx.UserContext(), nodeId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Node with all descendants has been restored",
+	})
+}
+
+func (controller *NodeControllerImpl) PurgeNode(ctx *fiber.Ctx) error {
+	nodeId := ctx.Params("nodeId")
+	err := controller.NodeService.PurgeNode(ctx.UserContext(), nodeId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(dto.ApiResponseSuccess{
+		Success: true,
+		Message: "Node with all descendants has been permanently deleted",
+	})
+}
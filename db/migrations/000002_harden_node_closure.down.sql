@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS node_closure_descendant_depth_idx;
+DROP INDEX IF EXISTS node_closure_ancestor_depth_idx;
+
+ALTER TABLE node_closure
+    DROP CONSTRAINT node_closure_ancestor_fkey,
+    DROP CONSTRAINT node_closure_descendant_fkey,
+    ADD CONSTRAINT node_closure_ancestor_fkey FOREIGN KEY (ancestor) REFERENCES nodes (id),
+    ADD CONSTRAINT node_closure_descendant_fkey FOREIGN KEY (descendant) REFERENCES nodes (id);
+
+ALTER TABLE node_closure
+    DROP CONSTRAINT node_closure_depth_check,
+    DROP CONSTRAINT node_closure_pkey,
+    ALTER COLUMN depth DROP NOT NULL,
+    ALTER COLUMN descendant DROP NOT NULL,
+    ALTER COLUMN ancestor DROP NOT NULL;
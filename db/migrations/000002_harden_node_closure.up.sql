@@ -0,0 +1,32 @@
+-- Remove incomplete and duplicated paths before adding constraints
+DELETE FROM node_closure
+WHERE ancestor IS NULL
+   OR descendant IS NULL
+   OR depth IS NULL;
+
+DELETE FROM node_closure a
+    USING node_closure b
+WHERE a.ctid < b.ctid
+  AND a.ancestor = b.ancestor
+  AND a.descendant = b.descendant;
+
+-- Every path is stored once and has a depth
+ALTER TABLE node_closure
+    ALTER COLUMN ancestor SET NOT NULL,
+    ALTER COLUMN descendant SET NOT NULL,
+    ALTER COLUMN depth SET NOT NULL,
+    ADD CONSTRAINT node_closure_pkey PRIMARY KEY (ancestor, descendant),
+    ADD CONSTRAINT node_closure_depth_check CHECK (depth >= 0);
+
+-- Paths are removed together with their nodes
+ALTER TABLE node_closure
+    DROP CONSTRAINT node_closure_ancestor_fkey,
+    DROP CONSTRAINT node_closure_descendant_fkey,
+    ADD CONSTRAINT node_closure_ancestor_fkey FOREIGN KEY (ancestor) REFERENCES nodes (id) ON DELETE CASCADE,
+    ADD CONSTRAINT node_closure_descendant_fkey FOREIGN KEY (descendant) REFERENCES nodes (id) ON DELETE CASCADE;
+
+-- Lookups by ancestor (children, subtree up to a depth)
+CREATE INDEX node_closure_ancestor_depth_idx ON node_closure (ancestor, depth, descendant);
+
+-- Lookups by descendant (ancestor path, parent)
+CREATE INDEX node_closure_descendant_depth_idx ON node_closure (descendant, depth, ancestor);
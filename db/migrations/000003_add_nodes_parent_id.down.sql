@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS nodes_parent_id_idx;
+
+ALTER TABLE nodes
+    DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,12 @@
+-- Keep the direct parent next to the closure table
+ALTER TABLE nodes
+    ADD COLUMN parent_id UUID REFERENCES nodes (id) ON DELETE CASCADE;
+
+-- Backfill from the depth 1 paths
+UPDATE nodes n
+SET parent_id = nc.ancestor
+FROM node_closure nc
+WHERE nc.descendant = n.id
+  AND nc.depth = 1;
+
+CREATE INDEX nodes_parent_id_idx ON nodes (parent_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS nodes_parent_id_position_idx;
+
+ALTER TABLE nodes
+    DROP COLUMN IF EXISTS position;
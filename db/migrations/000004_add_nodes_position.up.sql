@@ -0,0 +1,12 @@
+-- Gap-based ordering among siblings
+ALTER TABLE nodes
+    ADD COLUMN position BIGINT NOT NULL DEFAULT 0;
+
+-- Backfill keeping the previous newest first order
+UPDATE nodes n
+SET position = ranked.rn * 1024
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY parent_id ORDER BY created_at DESC, id) AS rn
+      FROM nodes) ranked
+WHERE ranked.id = n.id;
+
+CREATE INDEX nodes_parent_id_position_idx ON nodes (parent_id, position);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS nodes_external_id_key;
+
+ALTER TABLE nodes
+    DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,6 @@
+-- Optional key assigned by the system a node is synced from, unique across all trees
+-- since nodes carry no tenant, trashed nodes keep holding theirs until purged
+ALTER TABLE nodes
+    ADD COLUMN external_id VARCHAR(255);
+
+CREATE UNIQUE INDEX nodes_external_id_key ON nodes (external_id) WHERE external_id IS NOT NULL;
@@ -0,0 +1,14 @@
+-- First response of requests sent with an Idempotency-Key header, status_code stays NULL
+-- while the first request is still running
+CREATE TABLE idempotency_keys
+(
+    key           VARCHAR(255) PRIMARY KEY,
+    request_hash  CHAR(64)    NOT NULL,
+    status_code   INT,
+    content_type  VARCHAR(255),
+    response_body BYTEA,
+    created_at    TIMESTAMPTZ NOT NULL,
+    expires_at    TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
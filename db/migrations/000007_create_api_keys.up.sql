@@ -0,0 +1,13 @@
+-- API keys are stored as SHA-256 hashes, the plain key is only shown when it is created or rotated
+CREATE TABLE api_keys
+(
+    id         UUID         NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    key_prefix VARCHAR(16)  NOT NULL,
+    key_hash   CHAR(64)     NOT NULL,
+    scopes     VARCHAR(50)[] NOT NULL,
+    expires_at TIMESTAMP(0) WITH TIME ZONE,
+    revoked_at TIMESTAMP(0) WITH TIME ZONE,
+    created_at TIMESTAMP(0) WITH TIME ZONE,
+    updated_at TIMESTAMP(0) WITH TIME ZONE
+);
@@ -0,0 +1,2 @@
+ALTER TABLE nodes
+    ALTER COLUMN deleted_at TYPE TIMESTAMP(0) WITH TIME ZONE;
@@ -0,0 +1,4 @@
+-- Nodes trashed together share deleted_at, whole seconds let separate deletions merge into one
+-- trash entry and be restored together
+ALTER TABLE nodes
+    ALTER COLUMN deleted_at TYPE TIMESTAMP(6) WITH TIME ZONE;
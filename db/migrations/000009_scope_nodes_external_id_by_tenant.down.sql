@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS nodes_tenant_external_id_key;
+
+CREATE UNIQUE INDEX nodes_external_id_key ON nodes (external_id) WHERE external_id IS NOT NULL;
+
+ALTER TABLE nodes
+    DROP COLUMN IF EXISTS tenant;
@@ -0,0 +1,8 @@
+-- External IDs are unique per tenant of the caller that created the node, callers without a tenant
+-- (API keys) share the empty one
+ALTER TABLE nodes
+    ADD COLUMN tenant VARCHAR(255) NOT NULL DEFAULT '';
+
+DROP INDEX IF EXISTS nodes_external_id_key;
+
+CREATE UNIQUE INDEX nodes_tenant_external_id_key ON nodes (tenant, external_id) WHERE external_id IS NOT NULL;
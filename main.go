@@ -2,16 +2,26 @@ package main
 
 import (
 	"fmt"
+	"github.com/anhsbolic/closure-table-go/auth"
 	"github.com/anhsbolic/closure-table-go/config"
 	"github.com/anhsbolic/closure-table-go/middleware"
 	"github.com/anhsbolic/closure-table-go/pkg"
 	"github.com/anhsbolic/closure-table-go/routes"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/valyala/fasthttp"
+	"os"
+	"strings"
 	"time"
 )
 
 func main() {
+	// Run Maintenance Command
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1]))
+	}
+
 	// Get Config
 	env := config.GetEnvConfig()
 
@@ -25,22 +35,50 @@ func main() {
 		ErrorHandler: pkg.NewErrorHandler,
 	})
 
-	// Set Global Middleware
-	server.Use(middleware.XApiKeyMiddleware)
+	// Exports stream whole subtrees, so they get their own write timeout
+	server.Server().HeaderReceived = exportRequestConfig(env.GetDuration("EXPORT_WRITE_TIMEOUT"))
 
 	// Setup DB
 	db := pkg.NewDB()
 
-	// Setup Redis
-	// redisClient := pkg.NewRedisClient()
+	// Setup Redis, optional cache in front of tree reads and shared rate limit buckets
+	var redisClient *redis.Client
+	if env.GetBool("REDIS_ENABLED") {
+		redisClient = pkg.NewRedisClient()
+	}
+	cache := pkg.NewCache(redisClient)
+	rateLimiter := pkg.NewRateLimiter(redisClient)
+
+	// Set Global Middleware, requests without valid credentials are rate limited by IP then rejected
+	server.Use(middleware.NewAuthMiddleware(auth.NewAuthenticators(db)...))
+	server.Use(middleware.NewUnauthenticatedRateLimitMiddleware(rateLimiter))
+	server.Use(middleware.RequireAuthenticated)
 
 	// Setup Validator
 	validate := validator.New()
 
 	// Setup Routes
-	routes.InitNodeRoutes(server, db, validate)
+	routes.InitNodeRoutes(server, db, cache, rateLimiter, validate)
+	routes.InitAdminRoutes(server, db, rateLimiter, validate)
 
 	// Start Server
 	err := server.Listen(addr)
 	pkg.PanicIfError(err)
 }
+
+// exportRequestConfig gives GET /v1/nodes/:nodeId/export the write timeout, default 30m, in place of
+// the server-wide WriteTimeout, which fasthttp applies to the whole streamed response
+func exportRequestConfig(writeTimeout time.Duration) func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+	if writeTimeout <= 0 {
+		writeTimeout = time.Minute * 30
+	}
+
+	return func(header *fasthttp.RequestHeader) fasthttp.RequestConfig {
+		path, _, _ := strings.Cut(string(header.RequestURI()), "?")
+		path = strings.TrimSuffix(path, "/")
+		if !header.IsGet() || !strings.HasPrefix(path, "/v1/nodes/") || !strings.HasSuffix(path, "/export") {
+			return fasthttp.RequestConfig{}
+		}
+		return fasthttp.RequestConfig{WriteTimeout: writeTimeout}
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/anhsbolic/closure-table-go/auth"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewAuthMiddleware tries each authenticator in order and puts the first resolved principal on the
+// user context. Requests with missing or rejected credentials continue without a principal so they
+// can be rate limited by IP before RequireAuthenticated rejects them
+func NewAuthMiddleware(authenticators ...auth.Authenticator) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		for _, authenticator := range authenticators {
+			principal, err := authenticator.Authenticate(ctx)
+			if errors.Is(err, auth.ErrNoCredentials) {
+				continue
+			}
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				return ctx.Next()
+			}
+			if err != nil {
+				return err
+			}
+
+			// Next
+			ctx.SetUserContext(pkg.WithPrincipal(ctx.UserContext(), principal))
+			return ctx.Next()
+		}
+
+		return ctx.Next()
+	}
+}
+
+// RequireAuthenticated rejects requests that NewAuthMiddleware did not resolve a principal for
+func RequireAuthenticated(ctx *fiber.Ctx) error {
+	if _, ok := pkg.PrincipalFromContext(ctx.UserContext()); !ok {
+		return fiber.ErrUnauthorized
+	}
+
+	return ctx.Next()
+}
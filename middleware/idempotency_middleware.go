@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"github.com/anhsbolic/closure-table-go/config"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/anhsbolic/closure-table-go/repository"
+	"github.com/gofiber/fiber/v2"
+	"time"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	defaultIdempotencyKeysTTL = 24 * time.Hour
+)
+
+// NewIdempotencyMiddleware stores the first response of requests carrying an Idempotency-Key header
+// for IDEMPOTENCY_TTL and replays it on retries. Requests without the header pass through, 5xx
+// responses are not stored so the request can be retried
+func NewIdempotencyMiddleware(db *sql.DB) fiber.Handler {
+	// Get Config
+	env := config.GetEnvConfig()
+	ttl := env.GetDuration("IDEMPOTENCY_TTL")
+	if ttl <= 0 {
+		ttl = defaultIdempotencyKeysTTL
+	}
+
+	idempotencyKeyRepository := repository.NewIdempotencyKeyRepository()
+
+	return func(ctx *fiber.Ctx) error {
+		// Get Header
+		key := ctx.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return ctx.Next()
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			return fiber.NewError(fiber.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+		}
+		key = principalIdempotencyKey(ctx, key)
+
+		// Claim Key
+		now := time.Now()
+		idempotencyKey := domain.IdempotencyKey{
+			Key:         key,
+			RequestHash: requestHash(ctx),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		}
+		isClaimed, err := idempotencyKeyRepository.Claim(ctx.UserContext(), db, idempotencyKey)
+		if err != nil {
+			return err
+		}
+
+		// Replay Stored Response
+		if !isClaimed {
+			storedKey, err := idempotencyKeyRepository.FindByKey(ctx.UserContext(), db, key)
+			if err != nil {
+				return err
+			}
+			if storedKey.Key != "" && storedKey.RequestHash != idempotencyKey.RequestHash {
+				return pkg.NewAppError(
+					fiber.StatusUnprocessableEntity,
+					"IDEMPOTENCY_KEY_REUSED",
+					"Idempotency-Key was already used for a different request",
+				)
+			}
+			if storedKey.Key == "" || !storedKey.StatusCode.Valid {
+				return pkg.NewAppError(
+					fiber.StatusConflict,
+					"IDEMPOTENCY_KEY_IN_PROGRESS",
+					"A request with this Idempotency-Key is still being processed",
+				)
+			}
+
+			ctx.Set(IdempotentReplayedHeader, "true")
+			ctx.Set(fiber.HeaderContentType, storedKey.ContentType.String)
+			return ctx.Status(int(storedKey.StatusCode.Int32)).Send(storedKey.ResponseBody)
+		}
+
+		// Run Handler, rendering its error so the final response can be stored
+		err = ctx.Next()
+		if err != nil {
+			err = ctx.App().Config().ErrorHandler(ctx, err)
+			if err != nil {
+				releaseIdempotencyKey(ctx, db, idempotencyKeyRepository, key)
+				return err
+			}
+		}
+
+		// Release Key On Server Errors
+		statusCode := ctx.Response().StatusCode()
+		if statusCode >= fiber.StatusInternalServerError {
+			releaseIdempotencyKey(ctx, db, idempotencyKeyRepository, key)
+			return nil
+		}
+
+		// Store Response
+		idempotencyKey.StatusCode = sql.NullInt32{Int32: int32(statusCode), Valid: true}
+		idempotencyKey.ContentType = sql.NullString{String: string(ctx.Response().Header.ContentType()), Valid: true}
+		idempotencyKey.ResponseBody = bytes.Clone(ctx.Response().Body())
+		err = idempotencyKeyRepository.SaveResponse(ctx.UserContext(), db, idempotencyKey)
+		if err != nil {
+			pkg.NewLogger().Error(err)
+		}
+
+		return nil
+	}
+}
+
+// principalIdempotencyKey scopes the key to the authenticated principal so callers cannot replay
+// each other's responses
+func principalIdempotencyKey(ctx *fiber.Ctx, key string) string {
+	principal, _ := pkg.PrincipalFromContext(ctx.UserContext())
+	hash := sha256.Sum256([]byte(principal.Identity() + "\x00" + key))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// requestHash fingerprints the method, URL and body so a key cannot be replayed for another request
+func requestHash(ctx *fiber.Ctx) string {
+	hash := sha256.New()
+	hash.Write([]byte(ctx.Method()))
+	hash.Write([]byte{0})
+	hash.Write([]byte(ctx.OriginalURL()))
+	hash.Write([]byte{0})
+	hash.Write(ctx.Body())
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// releaseIdempotencyKey drops a claimed key so the request can be retried
+func releaseIdempotencyKey(ctx *fiber.Ctx, db *sql.DB, idempotencyKeyRepository repository.IdempotencyKeyRepository, key string) {
+	err := idempotencyKeyRepository.Delete(ctx.UserContext(), db, key)
+	if err != nil {
+		pkg.NewLogger().Error(err)
+	}
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"fmt"
+	"github.com/anhsbolic/closure-table-go/config"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/gofiber/fiber/v2"
+	"math"
+	"strconv"
+	"time"
+)
+
+const (
+	RateLimitLimitHeader      = "X-RateLimit-Limit"
+	RateLimitRemainingHeader  = "X-RateLimit-Remaining"
+	RateLimitResetHeader      = "X-RateLimit-Reset"
+	defaultReadRatePerMinute  = 600
+	defaultReadBurst          = 60
+	defaultWriteRatePerMinute = 120
+	defaultWriteBurst         = 20
+)
+
+// RateLimitBudget is a token bucket configuration, reads and tree mutations are limited separately
+type RateLimitBudget string
+
+const (
+	RateLimitRead  RateLimitBudget = "read"
+	RateLimitWrite RateLimitBudget = "write"
+)
+
+// NewRateLimitMiddleware charges each request to the authenticated principal's bucket for the
+// budget, routes pick the budget so reads sent as POST are not charged as mutations. Limiter
+// failures are logged and let the request through
+func NewRateLimitMiddleware(limiter pkg.RateLimiter, budget RateLimitBudget) fiber.Handler {
+	bucket := rateLimitBucket(budget)
+
+	return func(ctx *fiber.Ctx) error {
+		return takeRateLimitToken(ctx, limiter, budget, bucket)
+	}
+}
+
+// NewUnauthenticatedRateLimitMiddleware charges requests without a principal to the client IP, so
+// missing or guessed credentials cannot get a fresh bucket per request. It runs after authentication
+// and before the request is rejected, these requests never reach a route so GET and HEAD count as
+// reads and everything else as mutations
+func NewUnauthenticatedRateLimitMiddleware(limiter pkg.RateLimiter) fiber.Handler {
+	readBucket := rateLimitBucket(RateLimitRead)
+	writeBucket := rateLimitBucket(RateLimitWrite)
+
+	return func(ctx *fiber.Ctx) error {
+		if _, ok := pkg.PrincipalFromContext(ctx.UserContext()); ok {
+			return ctx.Next()
+		}
+
+		if ctx.Method() == fiber.MethodGet || ctx.Method() == fiber.MethodHead {
+			return takeRateLimitToken(ctx, limiter, RateLimitRead, readBucket)
+		}
+		return takeRateLimitToken(ctx, limiter, RateLimitWrite, writeBucket)
+	}
+}
+
+// rateLimitBucket reads RATE_LIMIT_<BUDGET>_PER_MINUTE and RATE_LIMIT_<BUDGET>_BURST
+func rateLimitBucket(budget RateLimitBudget) pkg.TokenBucket {
+	// Get Config
+	env := config.GetEnvConfig()
+	ratePerMinute, burst := float64(defaultReadRatePerMinute), defaultReadBurst
+	prefix := "RATE_LIMIT_READ_"
+	if budget == RateLimitWrite {
+		ratePerMinute, burst = defaultWriteRatePerMinute, defaultWriteBurst
+		prefix = "RATE_LIMIT_WRITE_"
+	}
+	if configured := env.GetFloat64(prefix + "PER_MINUTE"); configured > 0 {
+		ratePerMinute = configured
+	}
+	if configured := env.GetInt(prefix + "BURST"); configured > 0 {
+		burst = configured
+	}
+
+	return pkg.TokenBucket{Rate: ratePerMinute / 60, Burst: burst}
+}
+
+func takeRateLimitToken(ctx *fiber.Ctx, limiter pkg.RateLimiter, budget RateLimitBudget, bucket pkg.TokenBucket) error {
+	// Take Token
+	key := "ratelimit:" + string(budget) + ":" + rateLimitIdentity(ctx)
+	result, err := limiter.Take(ctx.UserContext(), key, bucket)
+	if err != nil {
+		pkg.NewLogger().Error(err)
+		return ctx.Next()
+	}
+
+	ctx.Set(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+	ctx.Set(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+	ctx.Set(RateLimitResetHeader, strconv.Itoa(ceilSeconds(result.ResetAfter)))
+	if !result.Allowed {
+		retryAfter := ceilSeconds(result.RetryAfter)
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+		return pkg.NewAppError(
+			fiber.StatusTooManyRequests,
+			"RATE_LIMITED",
+			fmt.Sprintf("Rate limit exceeded, retry in %d seconds", retryAfter),
+		)
+	}
+
+	return ctx.Next()
+}
+
+// rateLimitIdentity is the verified principal, an API key or a JWT subject within its tenant, so
+// unverified bearer tokens never get a bucket of their own. Requests without one use the client IP
+func rateLimitIdentity(ctx *fiber.Ctx) string {
+	principal, ok := pkg.PrincipalFromContext(ctx.UserContext())
+	if !ok {
+		return "ip:" + ctx.IP()
+	}
+
+	return principal.Identity()
+}
+
+func ceilSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireScope rejects requests whose principal was not granted scope, it runs after authentication
+func RequireScope(scope string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		principal, ok := pkg.PrincipalFromContext(ctx.UserContext())
+		if !ok {
+			return fiber.ErrUnauthorized
+		}
+		if !principal.HasScope(scope) {
+			return pkg.NewAppError(
+				fiber.StatusForbidden,
+				"INSUFFICIENT_SCOPE",
+				"Caller is missing the "+scope+" scope",
+			)
+		}
+
+		return ctx.Next()
+	}
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+)
+
+type ApiKey struct {
+	ID        uuid.UUID    `db:"id" json:"id"`
+	Name      string       `db:"name" json:"name"`
+	KeyPrefix string       `db:"key_prefix" json:"key_prefix"`
+	KeyHash   string       `db:"key_hash" json:"key_hash"`
+	Scopes    []string     `db:"scopes" json:"scopes"`
+	ExpiresAt sql.NullTime `db:"expires_at,omitempty" json:"expires_at,omitempty"`
+	RevokedAt sql.NullTime `db:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt sql.NullTime `db:"created_at,omitempty" json:"created_at,omitempty"`
+	UpdatedAt sql.NullTime `db:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
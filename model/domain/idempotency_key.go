@@ -0,0 +1,16 @@
+package domain
+
+import (
+	"database/sql"
+	"time"
+)
+
+type IdempotencyKey struct {
+	Key          string         `db:"key" json:"key"`
+	RequestHash  string         `db:"request_hash" json:"request_hash"`
+	StatusCode   sql.NullInt32  `db:"status_code,omitempty" json:"status_code,omitempty"`
+	ContentType  sql.NullString `db:"content_type,omitempty" json:"content_type,omitempty"`
+	ResponseBody []byte         `db:"response_body,omitempty" json:"response_body,omitempty"`
+	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
+	ExpiresAt    time.Time      `db:"expires_at" json:"expires_at"`
+}
@@ -7,9 +7,13 @@ import (
 
 type Node struct {
 	ID          uuid.UUID      `db:"id" json:"id"`
+	ParentID    uuid.NullUUID  `db:"parent_id,omitempty" json:"parent_id,omitempty"`
+	ExternalID  sql.NullString `db:"external_id,omitempty" json:"external_id,omitempty"`
+	Tenant      string         `db:"tenant" json:"tenant"`
 	Title       string         `db:"title" json:"title"`
 	Type        string         `db:"type" json:"type"`
 	Description sql.NullString `db:"description,omitempty" json:"description,omitempty"`
+	Position    int64          `db:"position" json:"position"`
 	CreatedAt   sql.NullTime   `db:"created_at,omitempty" json:"created_at,omitempty"`
 	UpdatedAt   sql.NullTime   `db:"updated_at,omitempty" json:"updated_at,omitempty"`
 	DeletedAt   sql.NullTime   `db:"deleted_at,omitempty" json:"deleted_at,omitempty"`
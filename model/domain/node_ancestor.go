@@ -0,0 +1,9 @@
+package domain
+
+import "github.com/google/uuid"
+
+type NodeAncestor struct {
+	Descendant uuid.UUID `db:"descendant" json:"descendant"`
+	Node       Node      `db:"node" json:"node"`
+	Depth      int       `db:"depth" json:"depth"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+)
+
+type NodeClosureIssue struct {
+	Type       string        `db:"type" json:"type"`
+	Ancestor   uuid.NullUUID `db:"ancestor" json:"ancestor"`
+	Descendant uuid.UUID     `db:"descendant" json:"descendant"`
+	Depth      sql.NullInt64 `db:"depth" json:"depth"`
+	Count      int           `db:"count" json:"count"`
+	Total      int           `db:"total" json:"total"`
+}
@@ -0,0 +1,7 @@
+package domain
+
+type NodeDescendant struct {
+	Node        Node `db:"node" json:"node"`
+	Depth       int  `db:"depth" json:"depth"`
+	HasChildren bool `db:"has_children" json:"has_children"`
+}
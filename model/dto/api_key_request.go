@@ -0,0 +1,9 @@
+package dto
+
+import "time"
+
+type ApiKeyCreateRequest struct {
+	Name      string     `json:"name" form:"name" validate:"required,max=255"`
+	Scopes    []string   `json:"scopes" form:"scopes" validate:"required,min=1,dive,oneof=nodes:read nodes:write admin"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" form:"expires_at,omitempty"`
+}
@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/google/uuid"
+	"time"
+)
+
+type ApiKeyResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	KeyPrefix string     `json:"key_prefix"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt *time.Time `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+// ApiKeyCreatedResponse carries the plain key, it is not stored and cannot be shown again
+type ApiKeyCreatedResponse struct {
+	ApiKeyResponse
+	Key string `json:"key"`
+}
+
+func ToApiKeyResponse(apiKey domain.ApiKey) ApiKeyResponse {
+	return ApiKeyResponse{
+		ID:        apiKey.ID,
+		Name:      apiKey.Name,
+		KeyPrefix: apiKey.KeyPrefix,
+		Scopes:    apiKey.Scopes,
+		ExpiresAt: pkg.NullTimeToPointer(apiKey.ExpiresAt),
+		RevokedAt: pkg.NullTimeToPointer(apiKey.RevokedAt),
+		CreatedAt: pkg.NullTimeToPointer(apiKey.CreatedAt),
+		UpdatedAt: pkg.NullTimeToPointer(apiKey.UpdatedAt),
+	}
+}
+
+func ToApiKeyListResponse(apiKeys []domain.ApiKey) []ApiKeyResponse {
+	apiKeyListResponse := []ApiKeyResponse{}
+	for _, apiKey := range apiKeys {
+		apiKeyListResponse = append(apiKeyListResponse, ToApiKeyResponse(apiKey))
+	}
+
+	return apiKeyListResponse
+}
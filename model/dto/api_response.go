@@ -6,6 +6,13 @@ type ApiResponseSuccess struct {
 	Data    interface{} `json:"data"`
 }
 
+type ApiResponsePagination struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message"`
+	Data    interface{}    `json:"data"`
+	Meta    PaginationMeta `json:"meta"`
+}
+
 type ApiResponseFail struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"github.com/google/uuid"
+)
+
+type ClosureIssueResponse struct {
+	Type       string     `json:"type"`
+	Ancestor   *uuid.UUID `json:"ancestor"`
+	Descendant uuid.UUID  `json:"descendant"`
+	Depth      *int64     `json:"depth"`
+	Count      int        `json:"count"`
+}
+
+type ClosureVerifyResponse struct {
+	Valid      bool                   `json:"valid"`
+	IssueCount int                    `json:"issue_count"`
+	Issues     []ClosureIssueResponse `json:"issues"`
+}
+
+type ClosureRebuildResponse struct {
+	NodeCount       int         `json:"node_count"`
+	ClosureCount    int         `json:"closure_count"`
+	DetachedNodeIDs []uuid.UUID `json:"detached_node_ids"`
+}
+
+func ToClosureVerifyResponse(issues []domain.NodeClosureIssue) ClosureVerifyResponse {
+	closureVerifyResponse := ClosureVerifyResponse{
+		Valid:  len(issues) == 0,
+		Issues: []ClosureIssueResponse{},
+	}
+
+	for _, issue := range issues {
+		closureIssueResponse := ClosureIssueResponse{
+			Type:       issue.Type,
+			Descendant: issue.Descendant,
+			Count:      issue.Count,
+		}
+		if issue.Ancestor.Valid {
+			closureIssueResponse.Ancestor = &issue.Ancestor.UUID
+		}
+		if issue.Depth.Valid {
+			closureIssueResponse.Depth = &issue.Depth.Int64
+		}
+		closureVerifyResponse.IssueCount = issue.Total
+		closureVerifyResponse.Issues = append(closureVerifyResponse.Issues, closureIssueResponse)
+	}
+
+	return closureVerifyResponse
+}
@@ -1,18 +1,98 @@
 package dto
 
+type NodePositionRequest struct {
+	BeforeID *string `json:"before_id,omitempty" form:"before_id,omitempty" validate:"omitempty,excluded_with=AfterID Position,uuid"`
+	AfterID  *string `json:"after_id,omitempty" form:"after_id,omitempty" validate:"omitempty,excluded_with=Position,uuid"`
+	Position *int    `json:"position,omitempty" form:"position,omitempty" validate:"omitempty,min=0"`
+}
+
 type NodeCreateRequest struct {
-	Title       string  `json:"title" form:"title" validate:"required"`
+	Title       string  `json:"title" form:"title" validate:"required,max=255"`
 	Type        string  `json:"type" form:"type" validate:"required,oneof=note task reminder"`
 	Description *string `json:"description,omitempty" form:"description,omitempty"`
-	AncestorID  *string `json:"ancestor_id,omitempty" form:"ancestor_id,omitempty"`
+	AncestorID  *string `json:"ancestor_id,omitempty" form:"ancestor_id,omitempty" validate:"omitempty,uuid"`
+	ExternalID  *string `json:"external_id,omitempty" form:"external_id,omitempty" validate:"omitempty,min=1,max=255"`
+	NodePositionRequest
 }
 
 type NodeUpdateRequest struct {
-	Title       string  `json:"title" form:"title" validate:"required"`
+	Title       string  `json:"title" form:"title" validate:"required,max=255"`
+	Type        string  `json:"type" form:"type" validate:"required,oneof=note task reminder"`
+	Description *string `json:"description,omitempty" form:"description,omitempty"`
+}
+
+type NodeUpsertRequest struct {
+	Title       string  `json:"title" form:"title" validate:"required,max=255"`
 	Type        string  `json:"type" form:"type" validate:"required,oneof=note task reminder"`
 	Description *string `json:"description,omitempty" form:"description,omitempty"`
+	ParentID    *string `json:"parent_id" form:"parent_id" validate:"omitempty,uuid"`
+}
+
+const (
+	NodeDeleteModeCascade = "cascade"
+	NodeDeleteModePromote = "promote"
+)
+
+type NodeDeleteRequest struct {
+	Mode string `json:"mode" query:"mode" validate:"omitempty,oneof=cascade promote"`
 }
 
 type NodeMoveRequest struct {
-	ToAncestorID string `json:"to_ancestor_id" form:"to_ancestor_id" validate:"required"`
+	ToAncestorID *string `json:"to_ancestor_id" form:"to_ancestor_id" validate:"omitempty,uuid"`
+	NodePositionRequest
+}
+
+type NodeCopyRequest struct {
+	ToAncestorID *string `json:"to_ancestor_id" form:"to_ancestor_id" validate:"omitempty,uuid"`
+	NodePositionRequest
+}
+
+type NodeImportItem struct {
+	Title       string           `json:"title" form:"title" validate:"required,max=255"`
+	Type        string           `json:"type" form:"type" validate:"required,oneof=note task reminder"`
+	Description *string          `json:"description,omitempty" form:"description,omitempty"`
+	Children    []NodeImportItem `json:"children,omitempty" form:"children,omitempty" validate:"omitempty,dive"`
+}
+
+type NodeImportRequest struct {
+	ParentID *string          `json:"parent_id,omitempty" form:"parent_id,omitempty" validate:"omitempty,uuid"`
+	Nodes    []NodeImportItem `json:"nodes" form:"nodes" validate:"required,min=1,dive"`
+}
+
+const (
+	NodeOutlineFormatOPML     = "opml"
+	NodeOutlineFormatMarkdown = "markdown"
+)
+
+type NodeOutlineImportRequest struct {
+	ParentID *string `json:"parent_id,omitempty" query:"parent_id" validate:"omitempty,uuid"`
+	Format   string  `json:"format" params:"format" validate:"required,oneof=opml markdown"`
+}
+
+type NodeCSVImportRequest struct {
+	ParentID *string `json:"parent_id,omitempty" query:"parent_id" validate:"omitempty,uuid"`
+}
+
+const (
+	NodeExportFormatJSON     = "json"
+	NodeExportFormatNDJSON   = "ndjson"
+	NodeExportFormatCSV      = "csv"
+	NodeExportFormatOPML     = NodeOutlineFormatOPML
+	NodeExportFormatMarkdown = NodeOutlineFormatMarkdown
+)
+
+type NodeExportRequest struct {
+	Format string `json:"format" query:"format" validate:"omitempty,oneof=json ndjson csv opml markdown"`
+}
+
+type NodeLCARequest struct {
+	IDs []string `json:"ids" query:"ids" validate:"required,min=2,max=100,dive,uuid"`
+}
+
+type NodeAncestorBulkRequest struct {
+	NodeIDs []string `json:"node_ids" form:"node_ids" validate:"required,min=1,max=100,dive,uuid"`
+}
+
+type NodeTreeRequest struct {
+	MaxDepth int `json:"max_depth" query:"max_depth" validate:"omitempty,min=1"`
 }
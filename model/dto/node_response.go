@@ -9,6 +9,8 @@ import (
 
 type NodeCreatedResponse struct {
 	ID          uuid.UUID  `json:"id"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	ExternalID  *string    `json:"external_id"`
 	Title       string     `json:"title"`
 	Type        string     `json:"type"`
 	Description *string    `json:"description"`
@@ -18,6 +20,8 @@ type NodeCreatedResponse struct {
 func ToNodeCreatedResponse(node domain.Node) NodeCreatedResponse {
 	return NodeCreatedResponse{
 		ID:          node.ID,
+		ParentID:    pkg.NullUUIDToPointer(node.ParentID),
+		ExternalID:  pkg.NullStringToPointer(node.ExternalID),
 		Title:       node.Title,
 		Type:        node.Type,
 		Description: pkg.NullStringToPointer(node.Description),
@@ -25,8 +29,26 @@ func ToNodeCreatedResponse(node domain.Node) NodeCreatedResponse {
 	}
 }
 
+type NodeCopiedResponse struct {
+	ID        uuid.UUID               `json:"id"`
+	IDMapping map[uuid.UUID]uuid.UUID `json:"id_mapping"`
+}
+
+type NodeCSVImportedResponse struct {
+	IDs       []uuid.UUID          `json:"ids"`
+	Count     int                  `json:"count"`
+	IDMapping map[string]uuid.UUID `json:"id_mapping"`
+}
+
+type NodeImportedResponse struct {
+	IDs   []uuid.UUID `json:"ids"`
+	Count int         `json:"count"`
+}
+
 type NodeResponse struct {
 	ID          uuid.UUID  `json:"id"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	ExternalID  *string    `json:"external_id"`
 	Title       string     `json:"title"`
 	Type        string     `json:"type"`
 	Description *string    `json:"description"`
@@ -40,6 +62,8 @@ func ToNodePaginationResponse(nodes []domain.Node) []NodeResponse {
 	for _, node := range nodes {
 		nodeResponses = append(nodeResponses, NodeResponse{
 			ID:          node.ID,
+			ParentID:    pkg.NullUUIDToPointer(node.ParentID),
+			ExternalID:  pkg.NullStringToPointer(node.ExternalID),
 			Title:       node.Title,
 			Type:        node.Type,
 			Description: pkg.NullStringToPointer(node.Description),
@@ -54,6 +78,8 @@ func ToNodePaginationResponse(nodes []domain.Node) []NodeResponse {
 func ToNodeDetailResponse(node domain.Node) NodeResponse {
 	return NodeResponse{
 		ID:          node.ID,
+		ParentID:    pkg.NullUUIDToPointer(node.ParentID),
+		ExternalID:  pkg.NullStringToPointer(node.ExternalID),
 		Title:       node.Title,
 		Type:        node.Type,
 		Description: pkg.NullStringToPointer(node.Description),
@@ -61,3 +87,135 @@ func ToNodeDetailResponse(node domain.Node) NodeResponse {
 		UpdatedAt:   pkg.NullTimeToPointer(node.UpdatedAt),
 	}
 }
+
+type NodeTrashResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	Title       string     `json:"title"`
+	Type        string     `json:"type"`
+	Description *string    `json:"description"`
+	CreatedAt   *time.Time `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+}
+
+func ToNodeTrashResponse(nodes []domain.Node) []NodeTrashResponse {
+	var nodeTrashResponses []NodeTrashResponse
+
+	for _, node := range nodes {
+		nodeTrashResponses = append(nodeTrashResponses, NodeTrashResponse{
+			ID:          node.ID,
+			ParentID:    pkg.NullUUIDToPointer(node.ParentID),
+			Title:       node.Title,
+			Type:        node.Type,
+			Description: pkg.NullStringToPointer(node.Description),
+			CreatedAt:   pkg.NullTimeToPointer(node.CreatedAt),
+			UpdatedAt:   pkg.NullTimeToPointer(node.UpdatedAt),
+			DeletedAt:   pkg.NullTimeToPointer(node.DeletedAt),
+		})
+	}
+
+	return nodeTrashResponses
+}
+
+type NodeAncestorResponse struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+	Type  string    `json:"type"`
+	Depth int       `json:"depth"`
+}
+
+type NodePathResponse struct {
+	NodeID uuid.UUID              `json:"node_id"`
+	Path   []NodeAncestorResponse `json:"path"`
+}
+
+type NodePathToResponse struct {
+	Distance int                    `json:"distance"`
+	Path     []NodeAncestorResponse `json:"path"`
+}
+
+type NodeLCAResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	Title       string     `json:"title"`
+	Type        string     `json:"type"`
+	Description *string    `json:"description"`
+	CreatedAt   *time.Time `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	Depth       int        `json:"depth"`
+}
+
+func ToNodeLCAResponse(ancestor domain.NodeAncestor) NodeLCAResponse {
+	return NodeLCAResponse{
+		ID:          ancestor.Node.ID,
+		ParentID:    pkg.NullUUIDToPointer(ancestor.Node.ParentID),
+		Title:       ancestor.Node.Title,
+		Type:        ancestor.Node.Type,
+		Description: pkg.NullStringToPointer(ancestor.Node.Description),
+		CreatedAt:   pkg.NullTimeToPointer(ancestor.Node.CreatedAt),
+		UpdatedAt:   pkg.NullTimeToPointer(ancestor.Node.UpdatedAt),
+		Depth:       ancestor.Depth,
+	}
+}
+
+func ToNodeAncestorResponse(ancestors []domain.NodeAncestor) []NodeAncestorResponse {
+	nodeAncestorResponses := []NodeAncestorResponse{}
+
+	for _, ancestor := range ancestors {
+		nodeAncestorResponses = append(nodeAncestorResponses, NodeAncestorResponse{
+			ID:    ancestor.Node.ID,
+			Title: ancestor.Node.Title,
+			Type:  ancestor.Node.Type,
+			Depth: ancestor.Depth,
+		})
+	}
+
+	return nodeAncestorResponses
+}
+
+type NodeTreeResponse struct {
+	ID          uuid.UUID           `json:"id"`
+	ParentID    *uuid.UUID          `json:"parent_id"`
+	Title       string              `json:"title"`
+	Type        string              `json:"type"`
+	Description *string             `json:"description"`
+	CreatedAt   *time.Time          `json:"created_at"`
+	UpdatedAt   *time.Time          `json:"updated_at"`
+	Depth       int                 `json:"depth"`
+	HasChildren bool                `json:"has_children"`
+	Children    []*NodeTreeResponse `json:"children"`
+}
+
+// ToNodeTreeResponse nests descendants under their parents, descendants must be ordered parents first
+func ToNodeTreeResponse(descendants []domain.NodeDescendant) *NodeTreeResponse {
+	var root *NodeTreeResponse
+	nodeTreeResponses := map[uuid.UUID]*NodeTreeResponse{}
+
+	for _, descendant := range descendants {
+		nodeTreeResponse := &NodeTreeResponse{
+			ID:          descendant.Node.ID,
+			ParentID:    pkg.NullUUIDToPointer(descendant.Node.ParentID),
+			Title:       descendant.Node.Title,
+			Type:        descendant.Node.Type,
+			Description: pkg.NullStringToPointer(descendant.Node.Description),
+			CreatedAt:   pkg.NullTimeToPointer(descendant.Node.CreatedAt),
+			UpdatedAt:   pkg.NullTimeToPointer(descendant.Node.UpdatedAt),
+			Depth:       descendant.Depth,
+			HasChildren: descendant.HasChildren,
+			Children:    []*NodeTreeResponse{},
+		}
+		nodeTreeResponses[descendant.Node.ID] = nodeTreeResponse
+
+		if descendant.Depth == 0 {
+			root = nodeTreeResponse
+			continue
+		}
+		parent, ok := nodeTreeResponses[descendant.Node.ParentID.UUID]
+		if ok {
+			parent.Children = append(parent.Children, nodeTreeResponse)
+		}
+	}
+
+	return root
+}
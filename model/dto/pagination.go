@@ -0,0 +1,17 @@
+package dto
+
+const (
+	DefaultPaginationLimit = 20
+	MaxPaginationLimit     = 100
+)
+
+type PaginationRequest struct {
+	Limit  int `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+	Offset int `json:"offset" query:"offset" validate:"omitempty,min=0"`
+}
+
+type PaginationMeta struct {
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+	Total  int `json:"total"`
+}
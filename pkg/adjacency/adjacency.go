@@ -0,0 +1,157 @@
+package adjacency
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// maxExternalIDLength and maxTitleLength match the external_id and title columns of nodes
+const (
+	maxExternalIDLength = 255
+	maxTitleLength      = 255
+)
+
+var nodeTypes = map[string]bool{"note": true, "task": true, "reminder": true}
+
+// Row is one node of a flat adjacency list, Line is its line in the source document
+type Row struct {
+	Line             int
+	ExternalID       string
+	ParentExternalID string
+	Title            string
+	Type             string
+}
+
+// RowError describes why a single row cannot be imported
+type RowError struct {
+	Line       int    `json:"line"`
+	ExternalID string `json:"external_id,omitempty"`
+	Message    string `json:"message"`
+}
+
+// Errors holds every row error found in a document, ordered by line
+type Errors []RowError
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return fmt.Sprintf("line %d: %s", e[0].Line, e[0].Message)
+	}
+	return fmt.Sprintf("%d rows have errors", len(e))
+}
+
+// Sort validates the rows and orders them parents first, siblings keeping their document order.
+// Rows with an unknown parent are reported as orphans and rows whose parent chain loops as cycles
+func Sort(rows []Row) ([]Row, Errors) {
+	var errs Errors
+	index := map[string]int{}
+	children := map[string][]int{}
+	var roots []int
+
+	// Validate rows and index them by external ID
+	for i, row := range rows {
+		valid := true
+		if row.ExternalID == "" {
+			errs = append(errs, RowError{Line: row.Line, Message: "external_id is required"})
+			valid = false
+		} else if len(row.ExternalID) > maxExternalIDLength {
+			errs = append(errs, RowError{
+				Line:    row.Line,
+				Message: fmt.Sprintf("external_id is longer than %d characters", maxExternalIDLength),
+			})
+			valid = false
+		} else if first, ok := index[row.ExternalID]; ok {
+			errs = append(errs, RowError{
+				Line:       row.Line,
+				ExternalID: row.ExternalID,
+				Message:    fmt.Sprintf("external_id is already used on line %d", rows[first].Line),
+			})
+			valid = false
+		}
+		if row.Title == "" {
+			errs = append(errs, RowError{Line: row.Line, ExternalID: row.ExternalID, Message: "title is required"})
+		} else if utf8.RuneCountInString(row.Title) > maxTitleLength {
+			errs = append(errs, RowError{
+				Line:       row.Line,
+				ExternalID: row.ExternalID,
+				Message:    fmt.Sprintf("title is longer than %d characters", maxTitleLength),
+			})
+		}
+		if !nodeTypes[row.Type] {
+			errs = append(errs, RowError{
+				Line:       row.Line,
+				ExternalID: row.ExternalID,
+				Message:    fmt.Sprintf("type %q is not one of note, task, reminder", row.Type),
+			})
+		}
+		if valid {
+			index[row.ExternalID] = i
+		}
+	}
+	for i, row := range rows {
+		if index[row.ExternalID] != i || row.ExternalID == "" {
+			continue
+		}
+		if row.ParentExternalID == "" {
+			roots = append(roots, i)
+			continue
+		}
+		if _, ok := index[row.ParentExternalID]; !ok {
+			errs = append(errs, RowError{
+				Line:       row.Line,
+				ExternalID: row.ExternalID,
+				Message:    fmt.Sprintf("parent_external_id %q does not match any row", row.ParentExternalID),
+			})
+			continue
+		}
+		children[row.ParentExternalID] = append(children[row.ParentExternalID], i)
+	}
+
+	// Walk from the roots, parents before children
+	ordered := make([]Row, 0, len(rows))
+	reached := make([]bool, len(rows))
+	queue := roots
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+		reached[i] = true
+		ordered = append(ordered, rows[i])
+		queue = append(queue, children[rows[i].ExternalID]...)
+	}
+
+	// Rows left unreached either hang below an orphan or sit on a cycle
+	visited := make([]bool, len(rows))
+	for i := range rows {
+		if reached[i] || visited[i] || index[rows[i].ExternalID] != i {
+			continue
+		}
+		var path []int
+		onPath := map[int]int{}
+		current, ok := i, true
+		for ok && !reached[current] && !visited[current] {
+			visited[current] = true
+			onPath[current] = len(path)
+			path = append(path, current)
+			current, ok = index[rows[current].ParentExternalID]
+		}
+		start, onCycle := onPath[current]
+		if !ok || !onCycle {
+			continue
+		}
+		for _, j := range path[start:] {
+			errs = append(errs, RowError{
+				Line:       rows[j].Line,
+				ExternalID: rows[j].ExternalID,
+				Message:    "row is part of a parent cycle",
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(a, b int) bool {
+			return errs[a].Line < errs[b].Line
+		})
+		return nil, errs
+	}
+	return ordered, nil
+}
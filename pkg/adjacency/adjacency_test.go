@@ -0,0 +1,83 @@
+package adjacency
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSortRowErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []Row
+		want Errors
+	}{
+		{
+			name: "title longer than 255 characters",
+			rows: []Row{
+				{Line: 2, ExternalID: "a", Title: strings.Repeat("é", 255), Type: "note"},
+				{Line: 3, ExternalID: "b", Title: strings.Repeat("é", 256), Type: "note"},
+			},
+			want: Errors{
+				{Line: 3, ExternalID: "b", Message: "title is longer than 255 characters"},
+			},
+		},
+		{
+			name: "parent cycle",
+			rows: []Row{
+				{Line: 2, ExternalID: "root", Title: "Root", Type: "note"},
+				{Line: 3, ExternalID: "a", ParentExternalID: "c", Title: "A", Type: "note"},
+				{Line: 4, ExternalID: "b", ParentExternalID: "a", Title: "B", Type: "note"},
+				{Line: 5, ExternalID: "c", ParentExternalID: "b", Title: "C", Type: "note"},
+				{Line: 6, ExternalID: "d", ParentExternalID: "c", Title: "D", Type: "note"},
+			},
+			want: Errors{
+				{Line: 3, ExternalID: "a", Message: "row is part of a parent cycle"},
+				{Line: 4, ExternalID: "b", Message: "row is part of a parent cycle"},
+				{Line: 5, ExternalID: "c", Message: "row is part of a parent cycle"},
+			},
+		},
+		{
+			name: "orphan parent",
+			rows: []Row{
+				{Line: 2, ExternalID: "a", ParentExternalID: "missing", Title: "A", Type: "note"},
+				{Line: 3, ExternalID: "b", ParentExternalID: "a", Title: "B", Type: "note"},
+			},
+			want: Errors{
+				{Line: 2, ExternalID: "a", Message: `parent_external_id "missing" does not match any row`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ordered, errs := Sort(test.rows)
+			if ordered != nil {
+				t.Errorf("Sort() rows = %v, want nil", ordered)
+			}
+			if !reflect.DeepEqual(errs, test.want) {
+				t.Errorf("Sort() errors = %v, want %v", errs, test.want)
+			}
+		})
+	}
+}
+
+func TestSortParentsFirst(t *testing.T) {
+	rows := []Row{
+		{Line: 2, ExternalID: "b", ParentExternalID: "a", Title: "B", Type: "note"},
+		{Line: 3, ExternalID: "a", Title: "A", Type: "note"},
+		{Line: 4, ExternalID: "c", ParentExternalID: "b", Title: "C", Type: "task"},
+	}
+
+	ordered, errs := Sort(rows)
+	if errs != nil {
+		t.Fatalf("Sort() errors = %v", errs)
+	}
+	var ids []string
+	for _, row := range ordered {
+		ids = append(ids, row.ExternalID)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Sort() order = %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,69 @@
+package adjacency
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var csvColumns = []string{"external_id", "parent_external_id", "title", "type"}
+
+// ReadCSV reads rows from a CSV document whose header names the external_id, parent_external_id,
+// title and type columns, in any order and next to any other column
+func ReadCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	// Locate columns from the header
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("CSV document is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range csvColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %s column", name)
+		}
+	}
+
+	var rows []Row
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			i := columns[name]
+			if i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		rows = append(rows, Row{
+			Line:             line,
+			ExternalID:       field("external_id"),
+			ParentExternalID: field("parent_external_id"),
+			Title:            field("title"),
+			Type:             field("type"),
+		})
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("CSV document has no rows")
+	}
+
+	return rows, nil
+}
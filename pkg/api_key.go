@@ -0,0 +1,63 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/google/uuid"
+	"strings"
+)
+
+const (
+	ScopeNodesRead  = "nodes:read"
+	ScopeNodesWrite = "nodes:write"
+	ScopeAdmin      = "admin"
+)
+
+// apiKeyPrefix starts every generated key, it is followed by the key ID in hex and the secret
+const apiKeyPrefix = "ctk_"
+
+// GenerateApiKey returns a new plain key for the API key ID, formatted as ctk_<id>_<secret>
+func GenerateApiKey(id uuid.UUID) (string, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return apiKeyPrefix + strings.ReplaceAll(id.String(), "-", "") + "_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// ApiKeyDisplayPrefix is the part of a key that is safe to show in listings
+func ApiKeyDisplayPrefix(id uuid.UUID) string {
+	return apiKeyPrefix + strings.ReplaceAll(id.String(), "-", "")[:8]
+}
+
+// ParseApiKeyID returns the key ID embedded in a plain key, false when the key is not a generated one
+func ParseApiKeyID(key string) (uuid.UUID, bool) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return uuid.Nil, false
+	}
+	idHex, _, found := strings.Cut(strings.TrimPrefix(key, apiKeyPrefix), "_")
+	if !found || len(idHex) != 32 {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(idHex)
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+func HashApiKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// VerifyApiKeyHash compares the hash of key with a stored hash in constant time
+func VerifyApiKeyHash(key string, keyHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(HashApiKey(key)), []byte(keyHash)) == 1
+}
@@ -0,0 +1,133 @@
+package pkg
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/anhsbolic/closure-table-go/config"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+const defaultCacheTTL = 5 * time.Minute
+
+// Cache stores JSON encoded values under string keys. A Get that misses returns the generation of
+// the key, Delete bumps it and Set skips storing a value loaded before the key was deleted again
+type Cache interface {
+	Get(ctx context.Context, key string, value interface{}) (bool, string, error)
+	Set(ctx context.Context, key string, generation string, value interface{}) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// NewCache returns a Redis cache with entries expiring after CACHE_TTL, or a no-op cache when
+// Redis is disabled
+func NewCache(client *redis.Client) Cache {
+	if client == nil {
+		return NewNoopCache()
+	}
+
+	// Get Config
+	env := config.GetEnvConfig()
+	ttl := env.GetDuration("CACHE_TTL")
+	if ttl <= 0 {
+		ttl = defaultCacheTTL
+	}
+
+	return NewRedisCache(client, ttl)
+}
+
+type RedisCache struct {
+	Client *redis.Client
+	TTL    time.Duration
+}
+
+func NewRedisCache(client *redis.Client, ttl time.Duration) Cache {
+	return &RedisCache{
+		Client: client,
+		TTL:    ttl,
+	}
+}
+
+// cacheGenerationKey holds the number of times key has been deleted
+func cacheGenerationKey(key string) string {
+	return key + ":generation"
+}
+
+// cacheSetScript stores a value only while the generation of its key is the one read on the miss,
+// a missing generation reads as an empty string
+var cacheSetScript = redis.NewScript(`
+local generation = redis.call('GET', KEYS[2]) or ''
+if generation ~= ARGV[1] then
+	return 0
+end
+
+redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+return 1
+`)
+
+func (cache *RedisCache) Get(ctx context.Context, key string, value interface{}) (bool, string, error) {
+	values, err := cache.Client.MGet(ctx, key, cacheGenerationKey(key)).Result()
+	if err != nil {
+		return false, "", err
+	}
+
+	data, isCached := values[0].(string)
+	if !isCached {
+		generation, _ := values[1].(string)
+		return false, generation, nil
+	}
+
+	err = json.Unmarshal([]byte(data), value)
+	if err != nil {
+		return false, "", err
+	}
+
+	return true, "", nil
+}
+
+func (cache *RedisCache) Set(ctx context.Context, key string, generation string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{key, cacheGenerationKey(key)}
+	return cacheSetScript.Run(ctx, cache.Client, keys, generation, data, cache.TTL.Milliseconds()).Err()
+}
+
+// Delete drops the keys and bumps their generations, the generations outlive the values so a
+// read still loading when the key is deleted cannot store its stale value
+func (cache *RedisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	_, err := cache.Client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+		for _, key := range keys {
+			pipe.Incr(ctx, cacheGenerationKey(key))
+			pipe.PExpire(ctx, cacheGenerationKey(key), 2*cache.TTL)
+		}
+		return nil
+	})
+	return err
+}
+
+// NoopCache never holds anything, every read misses
+type NoopCache struct {
+}
+
+func NewNoopCache() Cache {
+	return &NoopCache{}
+}
+
+func (cache *NoopCache) Get(ctx context.Context, key string, value interface{}) (bool, string, error) {
+	return false, "", nil
+}
+
+func (cache *NoopCache) Set(ctx context.Context, key string, generation string, value interface{}) error {
+	return nil
+}
+
+func (cache *NoopCache) Delete(ctx context.Context, keys ...string) error {
+	return nil
+}
@@ -1,6 +1,9 @@
 package pkg
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+)
 
 func CloseRows(rows *sql.Rows) {
 	err := rows.Close()
@@ -9,3 +12,10 @@ func CloseRows(rows *sql.Rows) {
 		PanicIfError(err)
 	}
 }
+
+// DBTX is implemented by both *sql.DB and *sql.Tx, so reads can run inside or outside a transaction
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
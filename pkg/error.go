@@ -5,3 +5,28 @@ func PanicIfError(err error) {
 		panic(err)
 	}
 }
+
+// AppError is an error with an HTTP status, a machine-readable error code and optional details
+type AppError struct {
+	Code      int
+	ErrorCode string
+	Message   string
+	Details   interface{}
+}
+
+func NewAppError(code int, errorCode string, message string) *AppError {
+	return &AppError{
+		Code:      code,
+		ErrorCode: errorCode,
+		Message:   message,
+	}
+}
+
+func (e *AppError) Error() string {
+	return e.Message
+}
+
+func (e *AppError) WithDetails(details interface{}) *AppError {
+	e.Details = details
+	return e
+}
@@ -3,12 +3,28 @@ package pkg
 import (
 	"errors"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 )
 
 func NewErrorHandler(ctx *fiber.Ctx, err error) error {
 	// Init Logger
 	logger := NewLogger()
 
+	// Return if Application Error
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		response := fiber.Map{
+			"success":    false,
+			"message":    utils.StatusMessage(appErr.Code),
+			"error_code": appErr.ErrorCode,
+			"error":      appErr.Message,
+		}
+		if appErr.Details != nil {
+			response["errors"] = appErr.Details
+		}
+		return ctx.Status(appErr.Code).JSON(response)
+	}
+
 	// Status code defaults to 500
 	code := fiber.StatusInternalServerError
 
@@ -0,0 +1,64 @@
+package export
+
+import (
+	"encoding/csv"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"io"
+	"strconv"
+	"time"
+)
+
+var csvHeader = []string{"id", "parent_id", "depth", "title", "type", "description", "created_at"}
+
+// CSVEncoder writes one flat row per node after a header row
+type CSVEncoder struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func NewCSVEncoder(w io.Writer) *CSVEncoder {
+	return &CSVEncoder{writer: csv.NewWriter(w)}
+}
+
+func (e *CSVEncoder) Encode(descendant domain.NodeDescendant) error {
+	if !e.headerWritten {
+		err := e.writer.Write(csvHeader)
+		if err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+
+	node := descendant.Node
+	parentId := ""
+	if node.ParentID.Valid {
+		parentId = node.ParentID.UUID.String()
+	}
+	createdAt := ""
+	if node.CreatedAt.Valid {
+		createdAt = node.CreatedAt.Time.Format(time.RFC3339)
+	}
+
+	return e.writer.Write([]string{
+		node.ID.String(),
+		parentId,
+		strconv.Itoa(descendant.Depth),
+		node.Title,
+		node.Type,
+		node.Description.String,
+		createdAt,
+	})
+}
+
+func (e *CSVEncoder) Close() error {
+	if !e.headerWritten {
+		err := e.writer.Write(csvHeader)
+		if err != nil {
+			return err
+		}
+		e.headerWritten = true
+	}
+
+	e.writer.Flush()
+	return e.writer.Error()
+}
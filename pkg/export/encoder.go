@@ -0,0 +1,37 @@
+package export
+
+import (
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Encoder writes a subtree one node at a time, nodes must arrive in depth-first order
+// with depth relative to the exported root
+type Encoder interface {
+	Encode(descendant domain.NodeDescendant) error
+	Close() error
+}
+
+type Node struct {
+	ID          uuid.UUID  `json:"id"`
+	ParentID    *uuid.UUID `json:"parent_id"`
+	Depth       int        `json:"depth"`
+	Title       string     `json:"title"`
+	Type        string     `json:"type"`
+	Description *string    `json:"description"`
+	CreatedAt   *time.Time `json:"created_at"`
+}
+
+func ToNode(descendant domain.NodeDescendant) Node {
+	return Node{
+		ID:          descendant.Node.ID,
+		ParentID:    pkg.NullUUIDToPointer(descendant.Node.ParentID),
+		Depth:       descendant.Depth,
+		Title:       descendant.Node.Title,
+		Type:        descendant.Node.Type,
+		Description: pkg.NullStringToPointer(descendant.Node.Description),
+		CreatedAt:   pkg.NullTimeToPointer(descendant.Node.CreatedAt),
+	}
+}
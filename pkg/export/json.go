@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"io"
+)
+
+// JSONEncoder writes the subtree as one nested object, each node holding a children array
+type JSONEncoder struct {
+	w       io.Writer
+	depths  []int
+	encoded bool
+}
+
+func NewJSONEncoder(w io.Writer) *JSONEncoder {
+	return &JSONEncoder{w: w}
+}
+
+func (e *JSONEncoder) Encode(descendant domain.NodeDescendant) error {
+	// Close nodes that are not ancestors of this one
+	separator := ""
+	for len(e.depths) > 0 && e.depths[len(e.depths)-1] >= descendant.Depth {
+		e.depths = e.depths[:len(e.depths)-1]
+		_, err := io.WriteString(e.w, "]}")
+		if err != nil {
+			return err
+		}
+		separator = ","
+	}
+
+	// Open node, leaving its children array open
+	object, err := json.Marshal(ToNode(descendant))
+	if err != nil {
+		return err
+	}
+	object = bytes.TrimSuffix(object, []byte("}"))
+	_, err = io.WriteString(e.w, separator)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(object)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(e.w, `,"children":[`)
+	if err != nil {
+		return err
+	}
+	e.depths = append(e.depths, descendant.Depth)
+	e.encoded = true
+
+	return nil
+}
+
+// Close ends the open nodes, or writes an empty array when the subtree disappeared before streaming
+// so the body is still valid JSON
+func (e *JSONEncoder) Close() error {
+	if !e.encoded {
+		e.encoded = true
+		_, err := io.WriteString(e.w, "[]")
+		return err
+	}
+	for range e.depths {
+		_, err := io.WriteString(e.w, "]}")
+		if err != nil {
+			return err
+		}
+	}
+	e.depths = nil
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package export
+
+import (
+	"encoding/json"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"io"
+)
+
+// NDJSONEncoder writes one flat JSON object per line
+type NDJSONEncoder struct {
+	encoder *json.Encoder
+}
+
+func NewNDJSONEncoder(w io.Writer) *NDJSONEncoder {
+	return &NDJSONEncoder{encoder: json.NewEncoder(w)}
+}
+
+func (e *NDJSONEncoder) Encode(descendant domain.NodeDescendant) error {
+	return e.encoder.Encode(ToNode(descendant))
+}
+
+func (e *NDJSONEncoder) Close() error {
+	return nil
+}
@@ -2,6 +2,7 @@ package pkg
 
 import (
 	"database/sql"
+	"github.com/google/uuid"
 	"time"
 )
 
@@ -28,3 +29,11 @@ func NullTimeToTime(nt sql.NullTime) time.Time {
 	}
 	return time.Time{} // Or use a zero value or specific fallback
 }
+
+// NullUUIDToPointer Helper function to convert uuid.NullUUID to *uuid.UUID
+func NullUUIDToPointer(nu uuid.NullUUID) *uuid.UUID {
+	if nu.Valid {
+		return &nu.UUID
+	}
+	return nil
+}
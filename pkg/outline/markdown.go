@@ -0,0 +1,180 @@
+package outline
+
+import (
+	"bufio"
+	"database/sql"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// markdownIndent is the indentation written per depth level
+const markdownIndent = "  "
+
+type markdownEntry struct {
+	indent int
+	node   *Node
+}
+
+// ParseMarkdown reads an indented bullet list, "- [ ]" and "- [x]" items become tasks and
+// indented lines that are not bullets are appended to the description of the item above
+func ParseMarkdown(r io.Reader) ([]Node, error) {
+	root := &Node{}
+	stack := []markdownEntry{{indent: -1, node: root}}
+	var last *Node
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		content := strings.TrimLeft(text, " \t")
+		if content == "" {
+			continue
+		}
+		indent := markdownIndentWidth(text[:len(text)-len(content)])
+
+		// Continuation line of the previous item
+		title, ok := markdownBullet(content)
+		if !ok {
+			if last == nil {
+				return nil, &ParseError{Line: line, Message: "expected a list item"}
+			}
+			content = strings.TrimPrefix(content, `\`) // escaped by MarkdownEncoder
+			if last.Description.Valid {
+				last.Description.String += "\n" + content
+			} else {
+				last.Description = sql.NullString{String: content, Valid: true}
+			}
+			continue
+		}
+
+		nodeType := TypeNote
+		if checkbox, ok := markdownCheckbox(title); ok {
+			nodeType = TypeTask
+			title = strings.TrimSpace(strings.TrimPrefix(title, checkbox))
+		}
+		title = strings.TrimPrefix(title, `\`) // escaped by MarkdownEncoder
+		if title == "" {
+			return nil, &ParseError{Line: line, Message: "list item has no title"}
+		}
+		if utf8.RuneCountInString(title) > maxTitleLength {
+			return nil, &ParseError{Line: line, Message: titleTooLongMessage}
+		}
+
+		// Attach to the nearest item with a smaller indentation
+		for stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		parent := stack[len(stack)-1].node
+		parent.Children = append(parent.Children, Node{
+			Node: domain.Node{Title: title, Type: nodeType},
+		})
+		last = &parent.Children[len(parent.Children)-1]
+		stack = append(stack, markdownEntry{indent: indent, node: last})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(root.Children) == 0 {
+		return nil, &ParseError{Message: "document has no list items"}
+	}
+
+	return root.Children, nil
+}
+
+// markdownBullet returns the text after a "-", "*" or "+" bullet marker
+func markdownBullet(content string) (string, bool) {
+	for _, marker := range []string{"- ", "* ", "+ "} {
+		if strings.HasPrefix(content, marker) {
+			return strings.TrimSpace(content[len(marker):]), true
+		}
+	}
+	if content == "-" || content == "*" || content == "+" {
+		return "", true
+	}
+	return "", false
+}
+
+// markdownCheckbox returns the task checkbox a title starts with
+func markdownCheckbox(title string) (string, bool) {
+	for _, checkbox := range []string{"[ ] ", "[x] ", "[X] "} {
+		if strings.HasPrefix(title, checkbox) {
+			return checkbox, true
+		}
+	}
+	return "", false
+}
+
+// markdownIndentWidth counts leading whitespace, a tab counting as four spaces
+func markdownIndentWidth(prefix string) int {
+	width := 0
+	for _, r := range prefix {
+		if r == '\t' {
+			width += 4
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// MarkdownEncoder writes the subtree as an indented bullet list, tasks as unchecked checkboxes
+type MarkdownEncoder struct {
+	w io.Writer
+}
+
+func NewMarkdownEncoder(w io.Writer) *MarkdownEncoder {
+	return &MarkdownEncoder{w: w}
+}
+
+func (e *MarkdownEncoder) Encode(descendant domain.NodeDescendant) error {
+	indent := strings.Repeat(markdownIndent, descendant.Depth)
+	node := descendant.Node
+
+	var builder strings.Builder
+	builder.WriteString(indent)
+	builder.WriteString("- ")
+	if node.Type == TypeTask {
+		builder.WriteString("[ ] ")
+	}
+	// Escape titles that would read back as tasks
+	title := markdownLine(node.Title)
+	if _, isCheckbox := markdownCheckbox(title); isCheckbox || strings.HasPrefix(title, `\`) {
+		title = `\` + title
+	}
+	builder.WriteString(title)
+	builder.WriteString("\n")
+
+	// Description lines are indented one level deeper than the item
+	if node.Description.Valid {
+		for _, line := range strings.Split(node.Description.String, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			// Escape lines that would read back as list items
+			if _, isBullet := markdownBullet(line); isBullet || strings.HasPrefix(line, `\`) {
+				line = `\` + line
+			}
+			builder.WriteString(indent)
+			builder.WriteString(markdownIndent)
+			builder.WriteString(line)
+			builder.WriteString("\n")
+		}
+	}
+
+	_, err := io.WriteString(e.w, builder.String())
+	return err
+}
+
+func (e *MarkdownEncoder) Close() error {
+	return nil
+}
+
+// markdownLine keeps a title on a single line
+func markdownLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,47 @@
+package outline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/anhsbolic/closure-table-go/model/domain"
+)
+
+func TestMarkdownRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		typ   string
+	}{
+		{name: "unchecked checkbox title", title: "[ ] not a task", typ: TypeNote},
+		{name: "checked checkbox title", title: "[x] not done", typ: TypeNote},
+		{name: "bullet title", title: "- not a child", typ: TypeNote},
+		{name: "backslash title", title: `\ escaped`, typ: TypeNote},
+		{name: "task with checkbox title", title: "[ ] still a task", typ: TypeTask},
+		{name: "task with bullet title", title: "- still a task", typ: TypeTask},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var builder strings.Builder
+			encoder := NewMarkdownEncoder(&builder)
+			err := encoder.Encode(domain.NodeDescendant{
+				Node: domain.Node{Title: test.title, Type: test.typ},
+			})
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+
+			nodes, err := ParseMarkdown(strings.NewReader(builder.String()))
+			if err != nil {
+				t.Fatalf("ParseMarkdown(%q) error = %v", builder.String(), err)
+			}
+			if len(nodes) != 1 || len(nodes[0].Children) != 0 {
+				t.Fatalf("ParseMarkdown(%q) = %+v, want one node", builder.String(), nodes)
+			}
+			if nodes[0].Title != test.title || nodes[0].Type != test.typ {
+				t.Errorf("round trip = %q (%s), want %q (%s)", nodes[0].Title, nodes[0].Type, test.title, test.typ)
+			}
+		})
+	}
+}
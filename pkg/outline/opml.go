@@ -0,0 +1,170 @@
+package outline
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Body    opmlBody `xml:"body"`
+}
+
+type opmlBody struct {
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	Type     string        `xml:"type,attr"`
+	Note     string        `xml:"_note,attr"`
+	Status   string        `xml:"_status,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// ParseOPML reads the outline elements of an OPML body, the node type comes from the type
+// attribute when it is a known node type and outlines carrying a _status checkbox become tasks
+func ParseOPML(r io.Reader) ([]Node, error) {
+	var document opmlDocument
+	err := xml.NewDecoder(r).Decode(&document)
+	if err != nil {
+		return nil, &ParseError{Message: "invalid OPML: " + err.Error()}
+	}
+	if len(document.Body.Outlines) == 0 {
+		return nil, &ParseError{Message: "document has no outline elements"}
+	}
+
+	return opmlNodes(document.Body.Outlines, "")
+}
+
+// opmlNodes converts outlines below parentPath, the 1-based position path of their parent
+func opmlNodes(outlines []opmlOutline, parentPath string) ([]Node, error) {
+	nodes := make([]Node, 0, len(outlines))
+	for i, item := range outlines {
+		path := strconv.Itoa(i + 1)
+		if parentPath != "" {
+			path = parentPath + "." + path
+		}
+
+		title := strings.TrimSpace(item.Text)
+		if title == "" {
+			title = strings.TrimSpace(item.Title)
+		}
+		if title == "" {
+			return nil, &ParseError{Path: path, Message: "outline element has no text"}
+		}
+		if utf8.RuneCountInString(title) > maxTitleLength {
+			return nil, &ParseError{Path: path, Message: titleTooLongMessage}
+		}
+
+		nodeType := TypeNote
+		switch {
+		case item.Type == TypeNote || item.Type == TypeTask || item.Type == TypeReminder:
+			nodeType = item.Type
+		case item.Status != "":
+			nodeType = TypeTask
+		}
+
+		description := sql.NullString{Valid: false}
+		if item.Note != "" {
+			description = sql.NullString{String: item.Note, Valid: true}
+		}
+
+		children, err := opmlNodes(item.Outlines, path)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, Node{
+			Node: domain.Node{
+				Title:       title,
+				Type:        nodeType,
+				Description: description,
+			},
+			Children: children,
+		})
+	}
+
+	return nodes, nil
+}
+
+// OPMLEncoder writes the subtree as an OPML 2.0 document titled after the exported root
+type OPMLEncoder struct {
+	w             io.Writer
+	depths        []int
+	headerWritten bool
+}
+
+func NewOPMLEncoder(w io.Writer) *OPMLEncoder {
+	return &OPMLEncoder{w: w}
+}
+
+func (e *OPMLEncoder) Encode(descendant domain.NodeDescendant) error {
+	node := descendant.Node
+	if !e.headerWritten {
+		err := e.writeHeader(node.Title)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Close outlines that are not ancestors of this one
+	var builder strings.Builder
+	for len(e.depths) > 0 && e.depths[len(e.depths)-1] >= descendant.Depth {
+		e.depths = e.depths[:len(e.depths)-1]
+		builder.WriteString("</outline>")
+	}
+
+	// Open outline, leaving it open for children
+	builder.WriteString(`<outline text="`)
+	builder.WriteString(opmlAttr(node.Title))
+	builder.WriteString(`" type="`)
+	builder.WriteString(opmlAttr(node.Type))
+	builder.WriteString(`"`)
+	if node.Type == TypeTask {
+		builder.WriteString(` _status="unchecked"`)
+	}
+	if node.Description.Valid {
+		builder.WriteString(` _note="`)
+		builder.WriteString(opmlAttr(node.Description.String))
+		builder.WriteString(`"`)
+	}
+	builder.WriteString(">")
+	e.depths = append(e.depths, descendant.Depth)
+
+	_, err := io.WriteString(e.w, builder.String())
+	return err
+}
+
+func (e *OPMLEncoder) Close() error {
+	if !e.headerWritten {
+		err := e.writeHeader("")
+		if err != nil {
+			return err
+		}
+	}
+
+	closing := strings.Repeat("</outline>", len(e.depths)) + "</body></opml>\n"
+	e.depths = nil
+	_, err := io.WriteString(e.w, closing)
+	return err
+}
+
+func (e *OPMLEncoder) writeHeader(title string) error {
+	e.headerWritten = true
+	_, err := io.WriteString(e.w, xml.Header+
+		`<opml version="2.0"><head><title>`+opmlAttr(title)+`</title></head><body>`)
+	return err
+}
+
+// opmlAttr escapes text for use in an attribute value, newlines included
+func opmlAttr(text string) string {
+	var builder strings.Builder
+	_ = xml.EscapeText(&builder, []byte(text))
+	return builder.String()
+}
@@ -0,0 +1,42 @@
+package outline
+
+import (
+	"fmt"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+)
+
+const (
+	TypeNote     = "note"
+	TypeTask     = "task"
+	TypeReminder = "reminder"
+)
+
+// maxTitleLength matches the title column of nodes
+const maxTitleLength = 255
+
+var titleTooLongMessage = fmt.Sprintf("title is longer than %d characters", maxTitleLength)
+
+// Node is one parsed outline entry, only title, type and description of the embedded node are set
+type Node struct {
+	domain.Node
+	Children []Node
+}
+
+// ParseError points at the line, or for OPML the outline path such as "2.1", of the document
+// that could not be parsed
+type ParseError struct {
+	Line    int
+	Path    string
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	switch {
+	case e.Line != 0:
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	case e.Path != "":
+		return fmt.Sprintf("outline %s: %s", e.Path, e.Message)
+	default:
+		return e.Message
+	}
+}
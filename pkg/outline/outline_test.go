@@ -0,0 +1,38 @@
+package outline
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTitleTooLong(t *testing.T) {
+	tests := []struct {
+		name     string
+		parse    func(document string) ([]Node, error)
+		document string
+		want     string
+	}{
+		{
+			name:     "markdown",
+			parse:    func(document string) ([]Node, error) { return ParseMarkdown(strings.NewReader(document)) },
+			document: "- Root\n  - " + strings.Repeat("é", 256) + "\n",
+			want:     "line 2: title is longer than 255 characters",
+		},
+		{
+			name:  "opml",
+			parse: func(document string) ([]Node, error) { return ParseOPML(strings.NewReader(document)) },
+			document: `<opml version="2.0"><body><outline text="Root"/><outline text="Parent">` +
+				`<outline text="` + strings.Repeat("é", 256) + `"/></outline></body></opml>`,
+			want: "outline 2.1: title is longer than 255 characters",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.parse(test.document)
+			if err == nil || err.Error() != test.want {
+				t.Errorf("parse error = %v, want %q", err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package pkg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+)
+
+const (
+	PrincipalTypeApiKey = "api_key"
+	PrincipalTypeJWT    = "jwt"
+)
+
+// Principal is the authenticated caller of a request, Type tells which authenticator resolved it
+type Principal struct {
+	Type    string
+	Subject string
+	Name    string
+	Tenant  string
+	Roles   []string
+	Scopes  []string
+}
+
+type principalContextKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(Principal)
+	return principal, ok
+}
+
+// HasScope reports whether the principal was granted scope, admin grants every scope
+func (principal Principal) HasScope(scope string) bool {
+	return slices.Contains(principal.Scopes, ScopeAdmin) || slices.Contains(principal.Scopes, scope)
+}
+
+// Identity names the caller for per-caller keys, JWT subjects are only unique within their tenant
+func (principal Principal) Identity() string {
+	hash := sha256.Sum256([]byte(principal.Tenant + "\x00" + principal.Subject))
+	return principal.Type + ":" + hex.EncodeToString(hash[:16])
+}
@@ -0,0 +1,167 @@
+package pkg
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TokenBucket refills Rate tokens per second up to Burst tokens, each request takes one token
+type TokenBucket struct {
+	Rate  float64
+	Burst int
+}
+
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	ResetAfter time.Duration
+}
+
+// RateLimiter takes one token from the bucket stored under key
+type RateLimiter interface {
+	Take(ctx context.Context, key string, bucket TokenBucket) (RateLimitResult, error)
+}
+
+// NewRateLimiter returns a Redis rate limiter shared by every process, or an in-memory one limited
+// to the current process when Redis is disabled
+func NewRateLimiter(client *redis.Client) RateLimiter {
+	if client == nil {
+		return NewMemoryRateLimiter()
+	}
+
+	return NewRedisRateLimiter(client)
+}
+
+func newRateLimitResult(allowed bool, tokens float64, bucket TokenBucket) RateLimitResult {
+	result := RateLimitResult{
+		Allowed:    allowed,
+		Limit:      bucket.Burst,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: time.Duration((float64(bucket.Burst) - tokens) / bucket.Rate * float64(time.Second)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / bucket.Rate * float64(time.Second))
+	}
+
+	return result
+}
+
+// tokenBucketScript refills and takes from a bucket atomically, using the Redis clock so every
+// process agrees on the time
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(state[1])
+local updatedAt = tonumber(state[2])
+if tokens == nil or updatedAt == nil then
+	tokens = burst
+	updatedAt = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - updatedAt) * rate / 1000)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) * 1000 / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+type RedisRateLimiter struct {
+	Client *redis.Client
+}
+
+func NewRedisRateLimiter(client *redis.Client) RateLimiter {
+	return &RedisRateLimiter{
+		Client: client,
+	}
+}
+
+func (limiter *RedisRateLimiter) Take(ctx context.Context, key string, bucket TokenBucket) (RateLimitResult, error) {
+	values, err := tokenBucketScript.Run(ctx, limiter.Client, []string{key}, bucket.Rate, bucket.Burst).Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	allowed, _ := values[0].(int64)
+	tokensText, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensText, 64)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	return newRateLimitResult(allowed == 1, tokens, bucket), nil
+}
+
+// memoryRateLimiterPruneEvery is the number of takes between sweeps of refilled buckets
+const memoryRateLimiterPruneEvery = 1000
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	bucket    TokenBucket
+}
+
+type MemoryRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	takes   int
+}
+
+func NewMemoryRateLimiter() RateLimiter {
+	return &MemoryRateLimiter{
+		buckets: map[string]*memoryBucket{},
+	}
+}
+
+func (limiter *MemoryRateLimiter) Take(ctx context.Context, key string, bucket TokenBucket) (RateLimitResult, error) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+
+	now := time.Now()
+	limiter.takes++
+	if limiter.takes%memoryRateLimiterPruneEvery == 0 {
+		limiter.prune(now)
+	}
+
+	// Refill
+	state, ok := limiter.buckets[key]
+	if !ok {
+		state = &memoryBucket{tokens: float64(bucket.Burst), updatedAt: now}
+		limiter.buckets[key] = state
+	}
+	state.bucket = bucket
+	state.tokens = math.Min(float64(bucket.Burst), state.tokens+now.Sub(state.updatedAt).Seconds()*bucket.Rate)
+	state.updatedAt = now
+
+	// Take
+	allowed := state.tokens >= 1
+	if allowed {
+		state.tokens--
+	}
+
+	return newRateLimitResult(allowed, state.tokens, bucket), nil
+}
+
+// prune drops buckets that have refilled completely, they start full again anyway
+func (limiter *MemoryRateLimiter) prune(now time.Time) {
+	for key, state := range limiter.buckets {
+		refilled := state.tokens + now.Sub(state.updatedAt).Seconds()*state.bucket.Rate
+		if refilled >= float64(state.bucket.Burst) {
+			delete(limiter.buckets, key)
+		}
+	}
+}
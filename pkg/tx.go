@@ -1,15 +1,108 @@
 package pkg
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/anhsbolic/closure-table-go/config"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
 
-func CommitOrRollback(tx *sql.Tx) {
-	err := recover()
+// WithTx runs fn inside a transaction started with opts, it commits when fn returns nil
+// and rolls back when fn returns an error or panics
+func WithTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
 	if err != nil {
-		errorRollback := tx.Rollback()
-		PanicIfError(errorRollback)
-		panic(err)
-	} else {
-		errCommit := tx.Commit()
-		PanicIfError(errCommit)
+		return err
 	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	err = fn(tx)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil && !errors.Is(errRollback, sql.ErrTxDone) {
+			return errors.Join(err, errRollback)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// NewTxOptions builds transaction options from DB_TX_ISOLATION, an empty value keeps the database default
+func NewTxOptions() *sql.TxOptions {
+	// Get Config
+	env := config.GetEnvConfig()
+
+	isolationLevels := map[string]sql.IsolationLevel{
+		"":                 sql.LevelDefault,
+		"read_uncommitted": sql.LevelReadUncommitted,
+		"read_committed":   sql.LevelReadCommitted,
+		"repeatable_read":  sql.LevelRepeatableRead,
+		"serializable":     sql.LevelSerializable,
+	}
+	isolationLevel, ok := isolationLevels[strings.ToLower(env.GetString("DB_TX_ISOLATION"))]
+	if !ok {
+		PanicIfError(fmt.Errorf("unknown DB_TX_ISOLATION %q", env.GetString("DB_TX_ISOLATION")))
+	}
+
+	return &sql.TxOptions{Isolation: isolationLevel}
+}
+
+const (
+	maxTxAttempts  = 5
+	txRetryBackoff = 20 * time.Millisecond
+)
+
+// WithRetryTx runs fn through WithTx and retries it when Postgres reports a serialization
+// failure (40001) or a deadlock (40P01), waiting with an exponential backoff between attempts
+func WithRetryTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	logger := NewLogger()
+
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = WithTx(ctx, db, opts, fn)
+		if err == nil {
+			if attempt > 1 {
+				logger.WithField("attempt", attempt).Info("transaction committed after retry")
+			}
+			return nil
+		}
+
+		var pqErr *pq.Error
+		if !errors.As(err, &pqErr) || (pqErr.Code != "40001" && pqErr.Code != "40P01") {
+			return err
+		}
+		if attempt == maxTxAttempts {
+			break
+		}
+
+		backoff := txRetryBackoff << (attempt - 1)
+		backoff += time.Duration(rand.Int64N(int64(backoff)))
+		logger.WithFields(logrus.Fields{
+			"attempt": attempt,
+			"code":    string(pqErr.Code),
+			"backoff": backoff.String(),
+		}).Warn("retrying transaction")
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+
+	logger.WithField("attempt", maxTxAttempts).Error("transaction retries exhausted")
+	return err
 }
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+)
+
+type ApiKeyRepository interface {
+	Create(ctx context.Context, db *sql.DB, apiKey domain.ApiKey) (domain.ApiKey, error)
+	DetailByID(ctx context.Context, db *sql.DB, id string) (domain.ApiKey, error)
+	GetList(ctx context.Context, db *sql.DB) ([]domain.ApiKey, error)
+	UpdateKeyHash(ctx context.Context, db *sql.DB, id string, keyHash string, updatedAt sql.NullTime) (bool, error)
+	Revoke(ctx context.Context, db *sql.DB, id string, revokedAt sql.NullTime) error
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/lib/pq"
+)
+
+type ApiKeyRepositoryImpl struct {
+}
+
+func NewApiKeyRepository() ApiKeyRepository {
+	return &ApiKeyRepositoryImpl{}
+}
+
+func (repository *ApiKeyRepositoryImpl) Create(ctx context.Context, db *sql.DB, apiKey domain.ApiKey) (domain.ApiKey, error) {
+	query := `INSERT INTO api_keys (id, name, key_prefix, key_hash, scopes, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := db.ExecContext(ctx, query,
+		apiKey.ID,
+		apiKey.Name,
+		apiKey.KeyPrefix,
+		apiKey.KeyHash,
+		pq.Array(apiKey.Scopes),
+		apiKey.ExpiresAt,
+		apiKey.CreatedAt,
+	)
+	if err != nil {
+		return domain.ApiKey{}, err
+	}
+
+	return apiKey, nil
+}
+
+func (repository *ApiKeyRepositoryImpl) DetailByID(ctx context.Context, db *sql.DB, id string) (domain.ApiKey, error) {
+	query := `SELECT id, name, key_prefix, key_hash, scopes, expires_at, revoked_at, created_at, updated_at FROM api_keys WHERE id = $1`
+	row := db.QueryRowContext(ctx, query, id)
+
+	apiKey := domain.ApiKey{}
+	err := row.Scan(
+		&apiKey.ID,
+		&apiKey.Name,
+		&apiKey.KeyPrefix,
+		&apiKey.KeyHash,
+		pq.Array(&apiKey.Scopes),
+		&apiKey.ExpiresAt,
+		&apiKey.RevokedAt,
+		&apiKey.CreatedAt,
+		&apiKey.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ApiKey{}, nil
+	}
+	if err != nil {
+		return domain.ApiKey{}, err
+	}
+
+	return apiKey, nil
+}
+
+func (repository *ApiKeyRepositoryImpl) GetList(ctx context.Context, db *sql.DB) ([]domain.ApiKey, error) {
+	query := `SELECT id, name, key_prefix, scopes, expires_at, revoked_at, created_at, updated_at FROM api_keys ORDER BY created_at, id`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.CloseRows(rows)
+
+	var apiKeys []domain.ApiKey
+	for rows.Next() {
+		apiKey := domain.ApiKey{}
+		err := rows.Scan(
+			&apiKey.ID,
+			&apiKey.Name,
+			&apiKey.KeyPrefix,
+			pq.Array(&apiKey.Scopes),
+			&apiKey.ExpiresAt,
+			&apiKey.RevokedAt,
+			&apiKey.CreatedAt,
+			&apiKey.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, nil
+}
+
+func (repository *ApiKeyRepositoryImpl) UpdateKeyHash(ctx context.Context, db *sql.DB, id string, keyHash string, updatedAt sql.NullTime) (bool, error) {
+	// Revoked keys stay revoked
+	query := `UPDATE api_keys SET key_hash = $1, updated_at = $2 WHERE id = $3 AND revoked_at IS NULL`
+	result, err := db.ExecContext(ctx, query, keyHash, updatedAt, id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (repository *ApiKeyRepositoryImpl) Revoke(ctx context.Context, db *sql.DB, id string, revokedAt sql.NullTime) error {
+	query := `UPDATE api_keys SET revoked_at = $1, updated_at = $1 WHERE id = $2 AND revoked_at IS NULL`
+	_, err := db.ExecContext(ctx, query, revokedAt, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
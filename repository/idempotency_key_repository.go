@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"time"
+)
+
+type IdempotencyKeyRepository interface {
+	Claim(ctx context.Context, db *sql.DB, idempotencyKey domain.IdempotencyKey) (bool, error)
+	FindByKey(ctx context.Context, db *sql.DB, key string) (domain.IdempotencyKey, error)
+	SaveResponse(ctx context.Context, db *sql.DB, idempotencyKey domain.IdempotencyKey) error
+	Delete(ctx context.Context, db *sql.DB, key string) error
+	DeleteExpired(ctx context.Context, db *sql.DB, now time.Time) (int64, error)
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"time"
+)
+
+type IdempotencyKeyRepositoryImpl struct {
+}
+
+func NewIdempotencyKeyRepository() IdempotencyKeyRepository {
+	return &IdempotencyKeyRepositoryImpl{}
+}
+
+func (repository *IdempotencyKeyRepositoryImpl) Claim(ctx context.Context, db *sql.DB, idempotencyKey domain.IdempotencyKey) (bool, error) {
+	// Insert a pending key, taking over an expired one
+	query := `INSERT INTO idempotency_keys (key, request_hash, created_at, expires_at) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (key) DO UPDATE
+			    SET request_hash  = EXCLUDED.request_hash,
+			        status_code   = NULL,
+			        content_type  = NULL,
+			        response_body = NULL,
+			        created_at    = EXCLUDED.created_at,
+			        expires_at    = EXCLUDED.expires_at
+			    WHERE idempotency_keys.expires_at <= EXCLUDED.created_at`
+	result, err := db.ExecContext(ctx, query,
+		idempotencyKey.Key,
+		idempotencyKey.RequestHash,
+		idempotencyKey.CreatedAt,
+		idempotencyKey.ExpiresAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (repository *IdempotencyKeyRepositoryImpl) FindByKey(ctx context.Context, db *sql.DB, key string) (domain.IdempotencyKey, error) {
+	query := `SELECT key, request_hash, status_code, content_type, response_body, created_at, expires_at
+			FROM idempotency_keys
+			WHERE key = $1`
+	row := db.QueryRowContext(ctx, query, key)
+
+	idempotencyKey := domain.IdempotencyKey{}
+	err := row.Scan(
+		&idempotencyKey.Key,
+		&idempotencyKey.RequestHash,
+		&idempotencyKey.StatusCode,
+		&idempotencyKey.ContentType,
+		&idempotencyKey.ResponseBody,
+		&idempotencyKey.CreatedAt,
+		&idempotencyKey.ExpiresAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.IdempotencyKey{}, nil
+	}
+	if err != nil {
+		return domain.IdempotencyKey{}, err
+	}
+
+	return idempotencyKey, nil
+}
+
+func (repository *IdempotencyKeyRepositoryImpl) SaveResponse(ctx context.Context, db *sql.DB, idempotencyKey domain.IdempotencyKey) error {
+	query := `UPDATE idempotency_keys SET status_code = $1, content_type = $2, response_body = $3 WHERE key = $4`
+	_, err := db.ExecContext(ctx, query,
+		idempotencyKey.StatusCode,
+		idempotencyKey.ContentType,
+		idempotencyKey.ResponseBody,
+		idempotencyKey.Key,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *IdempotencyKeyRepositoryImpl) Delete(ctx context.Context, db *sql.DB, key string) error {
+	query := `DELETE FROM idempotency_keys WHERE key = $1`
+	_, err := db.ExecContext(ctx, query, key)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *IdempotencyKeyRepositoryImpl) DeleteExpired(ctx context.Context, db *sql.DB, now time.Time) (int64, error) {
+	query := `DELETE FROM idempotency_keys WHERE expires_at <= $1`
+	result, err := db.ExecContext(ctx, query, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
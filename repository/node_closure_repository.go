@@ -4,12 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"github.com/anhsbolic/closure-table-go/model/domain"
+	"github.com/anhsbolic/closure-table-go/pkg"
 )
 
 type NodeClosureRepository interface {
 	Save(ctx context.Context, tx *sql.Tx, nodeClosures domain.NodeClosure) (domain.NodeClosure, error)
-	DeleteByDescendantIds(ctx context.Context, tx *sql.Tx, descendantIds []string) error
 	FindDescendantIdsByAncestor(ctx context.Context, tx *sql.Tx, ancestorId string) ([]string, error)
-	FindByDescendant(ctx context.Context, db *sql.DB, nodeID string) ([]domain.NodeClosure, error)
-	GetNewClosures(ctx context.Context, tx *sql.Tx, nodeId string, newAncestorId string) ([]domain.NodeClosure, error)
+	FindAncestorIdsByDescendants(ctx context.Context, db pkg.DBTX, descendantIds []string) ([]string, error)
+	FindByDescendant(ctx context.Context, db pkg.DBTX, nodeID string) ([]domain.NodeClosure, error)
+	CheckByAncestorAndDescendant(ctx context.Context, db pkg.DBTX, ancestorId string, descendantId string) (bool, error)
+	DeleteAncestorLinks(ctx context.Context, tx *sql.Tx, nodeId string) error
+	DetachAndPromoteChildren(ctx context.Context, tx *sql.Tx, nodeId string) error
+	SaveAncestorLinks(ctx context.Context, tx *sql.Tx, nodeId string, newAncestorId string) error
+	SaveCopies(ctx context.Context, tx *sql.Tx, oldIds []string, newIds []string) error
+	SaveBulk(ctx context.Context, tx *sql.Tx, nodeClosures []domain.NodeClosure) error
+	DeleteAll(ctx context.Context, tx *sql.Tx) error
+	FindIntegrityIssues(ctx context.Context, db pkg.DBTX, limit int) ([]domain.NodeClosureIssue, error)
 }
@@ -8,6 +8,8 @@ import (
 	"github.com/lib/pq"
 )
 
+const saveBulkBatchSize = 5000
+
 type NodeClosureRepositoryImpl struct {
 }
 
@@ -28,16 +30,6 @@ func (repository *NodeClosureRepositoryImpl) Save(ctx context.Context, tx *sql.T
 	return nodeClosure, nil
 }
 
-func (repository *NodeClosureRepositoryImpl) DeleteByDescendantIds(ctx context.Context, tx *sql.Tx, descendantIds []string) error {
-	query := `DELETE FROM node_closure WHERE descendant = ANY($1)`
-	_, err := tx.ExecContext(ctx, query, pq.Array(descendantIds))
-
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
 func (repository *NodeClosureRepositoryImpl) FindDescendantIdsByAncestor(ctx context.Context, tx *sql.Tx, ancestorId string) ([]string, error) {
 	query := `SELECT descendant FROM node_closure WHERE ancestor = $1`
 	rows, err := tx.QueryContext(ctx, query, ancestorId)
@@ -59,7 +51,28 @@ func (repository *NodeClosureRepositoryImpl) FindDescendantIdsByAncestor(ctx con
 	return descendantIds, nil
 }
 
-func (repository *NodeClosureRepositoryImpl) FindByDescendant(ctx context.Context, db *sql.DB, nodeID string) ([]domain.NodeClosure, error) {
+func (repository *NodeClosureRepositoryImpl) FindAncestorIdsByDescendants(ctx context.Context, db pkg.DBTX, descendantIds []string) ([]string, error) {
+	query := `SELECT DISTINCT ancestor FROM node_closure WHERE descendant = ANY($1)`
+	rows, err := db.QueryContext(ctx, query, pq.Array(descendantIds))
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.CloseRows(rows)
+
+	var ancestorIds []string
+	for rows.Next() {
+		var ancestorID string
+		err := rows.Scan(&ancestorID)
+		if err != nil {
+			return nil, err
+		}
+		ancestorIds = append(ancestorIds, ancestorID)
+	}
+
+	return ancestorIds, nil
+}
+
+func (repository *NodeClosureRepositoryImpl) FindByDescendant(ctx context.Context, db pkg.DBTX, nodeID string) ([]domain.NodeClosure, error) {
 	query := `SELECT ancestor, descendant, depth FROM node_closure WHERE descendant = $1 ORDER BY depth`
 	rows, err := db.QueryContext(ctx, query, nodeID)
 	if err != nil {
@@ -80,32 +93,237 @@ func (repository *NodeClosureRepositoryImpl) FindByDescendant(ctx context.Contex
 	return nodeClosures, nil
 }
 
-func (repository *NodeClosureRepositoryImpl) GetNewClosures(ctx context.Context, tx *sql.Tx, nodeId string, newAncestorId string) ([]domain.NodeClosure, error) {
-	query := `SELECT
+func (repository *NodeClosureRepositoryImpl) CheckByAncestorAndDescendant(ctx context.Context, db pkg.DBTX, ancestorId string, descendantId string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM node_closure WHERE ancestor = $1 AND descendant = $2)`
+
+	var isExist bool
+	err := db.QueryRowContext(ctx, query, ancestorId, descendantId).Scan(&isExist)
+	if err != nil {
+		return false, err
+	}
+
+	return isExist, nil
+}
+
+func (repository *NodeClosureRepositoryImpl) DeleteAncestorLinks(ctx context.Context, tx *sql.Tx, nodeId string) error {
+	// Delete paths from the node's ancestors into its subtree, keeping the subtree's own paths
+	query := `DELETE FROM node_closure AS link
+				USING node_closure AS super_tree, node_closure AS sub_tree
+			WHERE link.ancestor = super_tree.ancestor
+			  AND link.descendant = sub_tree.descendant
+			  AND super_tree.descendant = $1
+			  AND super_tree.depth > 0
+			  AND sub_tree.ancestor = $1`
+	_, err := tx.ExecContext(ctx, query, nodeId)
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repository *NodeClosureRepositoryImpl) DetachAndPromoteChildren(ctx context.Context, tx *sql.Tx, nodeId string) error {
+	// Paths from the node's ancestors to its descendants become one level shorter
+	query := `UPDATE node_closure AS link
+			SET depth = link.depth - 1
+			FROM node_closure AS super_tree, node_closure AS sub_tree
+			WHERE link.ancestor = super_tree.ancestor
+			  AND link.descendant = sub_tree.descendant
+			  AND super_tree.descendant = $1
+			  AND super_tree.depth > 0
+			  AND sub_tree.ancestor = $1
+			  AND sub_tree.depth > 0`
+	_, err := tx.ExecContext(ctx, query, nodeId)
+	if err != nil {
+		return err
+	}
+
+	// Remove the paths from the node to its former descendants, its ancestor paths and self
+	// reference stay so a restore puts it back in place
+	query = `DELETE FROM node_closure
+			WHERE ancestor = $1
+			  AND descendant != $1`
+	_, err = tx.ExecContext(ctx, query, nodeId)
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repository *NodeClosureRepositoryImpl) SaveAncestorLinks(ctx context.Context, tx *sql.Tx, nodeId string, newAncestorId string) error {
+	// Link every ancestor of the new ancestor (including itself) to every node in the subtree
+	query := `INSERT INTO node_closure (ancestor, descendant, depth)
+			SELECT
 				super_tree.ancestor,
 				sub_tree.descendant,
-				super_tree.depth + sub_tree.depth + 1 as depth
+				super_tree.depth + sub_tree.depth + 1
 			FROM
 				node_closure AS super_tree
-			JOIN
-				node_closure AS sub_tree ON sub_tree.ancestor = $1
+			CROSS JOIN
+				node_closure AS sub_tree
 			WHERE
-				super_tree.descendant = $2`
-	rows, err := tx.QueryContext(ctx, query, nodeId, newAncestorId)
+				super_tree.descendant = $2
+			  AND sub_tree.ancestor = $1`
+	_, err := tx.ExecContext(ctx, query, nodeId, newAncestorId)
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repository *NodeClosureRepositoryImpl) SaveCopies(ctx context.Context, tx *sql.Tx, oldIds []string, newIds []string) error {
+	// Reproduce the paths between copied nodes on their copies
+	query := `INSERT INTO node_closure (ancestor, descendant, depth)
+			SELECT ma.new_id, md.new_id, nc.depth
+			FROM node_closure nc
+			    JOIN unnest($1::uuid[], $2::uuid[]) AS ma(old_id, new_id) ON ma.old_id = nc.ancestor
+			    JOIN unnest($1::uuid[], $2::uuid[]) AS md(old_id, new_id) ON md.old_id = nc.descendant`
+	_, err := tx.ExecContext(ctx, query, pq.Array(oldIds), pq.Array(newIds))
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repository *NodeClosureRepositoryImpl) SaveBulk(ctx context.Context, tx *sql.Tx, nodeClosures []domain.NodeClosure) error {
+	query := `INSERT INTO node_closure (ancestor, descendant, depth)
+			SELECT * FROM unnest($1::uuid[], $2::uuid[], $3::int[])`
+
+	for start := 0; start < len(nodeClosures); start += saveBulkBatchSize {
+		end := min(start+saveBulkBatchSize, len(nodeClosures))
+
+		ancestors := make([]string, 0, end-start)
+		descendants := make([]string, 0, end-start)
+		depths := make([]int64, 0, end-start)
+		for _, nodeClosure := range nodeClosures[start:end] {
+			ancestors = append(ancestors, nodeClosure.Ancestor.String())
+			descendants = append(descendants, nodeClosure.Descendant.String())
+			depths = append(depths, int64(nodeClosure.Depth))
+		}
+
+		_, err := tx.ExecContext(ctx, query, pq.Array(ancestors), pq.Array(descendants), pq.Array(depths))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repository *NodeClosureRepositoryImpl) DeleteAll(ctx context.Context, tx *sql.Tx) error {
+	query := `DELETE FROM node_closure`
+	_, err := tx.ExecContext(ctx, query)
+
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (repository *NodeClosureRepositoryImpl) FindIntegrityIssues(ctx context.Context, db pkg.DBTX, limit int) ([]domain.NodeClosureIssue, error) {
+	query := `WITH issues AS (
+				-- every node needs its self reference
+				SELECT 'missing_self_row' AS type, NULL::uuid AS ancestor, n.id AS descendant, NULL::int AS depth, 1::bigint AS count
+				FROM nodes n
+				WHERE NOT EXISTS (SELECT 1
+				                  FROM node_closure nc
+				                  WHERE nc.ancestor = n.id
+				                    AND nc.descendant = n.id
+				                    AND nc.depth = 0)
+				UNION ALL
+				-- every path is stored once
+				SELECT 'duplicate_path', ancestor, descendant, NULL, COUNT(*)
+				FROM node_closure
+				GROUP BY ancestor, descendant
+				HAVING COUNT(*) > 1
+				UNION ALL
+				-- only self references have depth 0
+				SELECT 'invalid_depth', ancestor, descendant, depth, 1
+				FROM node_closure
+				WHERE (ancestor = descendant) != (depth = 0)
+				   OR depth < 0
+				UNION ALL
+				-- every node has at most one parent
+				SELECT 'multiple_parents', NULL, descendant, 1, COUNT(DISTINCT ancestor)
+				FROM node_closure
+				WHERE depth = 1
+				GROUP BY descendant
+				HAVING COUNT(DISTINCT ancestor) > 1
+				UNION ALL
+				-- no node is both ancestor and descendant of another
+				SELECT 'cycle', a.ancestor, a.descendant, a.depth, 1
+				FROM node_closure a
+				    JOIN node_closure b ON b.ancestor = a.descendant AND b.descendant = a.ancestor
+				WHERE a.ancestor != a.descendant
+				UNION ALL
+				-- every path longer than one goes through the descendant's parent
+				SELECT 'inconsistent_depth', l.ancestor, l.descendant, l.depth, 1
+				FROM node_closure l
+				WHERE l.depth > 1
+				  AND NOT EXISTS (SELECT 1
+				                  FROM node_closure p
+				                      JOIN node_closure ap ON ap.descendant = p.ancestor
+				                  WHERE p.descendant = l.descendant
+				                    AND p.depth = 1
+				                    AND ap.ancestor = l.ancestor
+				                    AND ap.depth = l.depth - 1)
+				UNION ALL
+				-- the depth 1 path matches the node's parent_id
+				SELECT 'parent_mismatch', n.parent_id, n.id, 1, 1
+				FROM nodes n
+				WHERE (n.parent_id IS NOT NULL
+				    AND NOT EXISTS (SELECT 1
+				                    FROM node_closure nc
+				                    WHERE nc.ancestor = n.parent_id
+				                      AND nc.descendant = n.id
+				                      AND nc.depth = 1))
+				   OR (n.parent_id IS NULL
+				    AND EXISTS (SELECT 1
+				                FROM node_closure nc
+				                WHERE nc.descendant = n.id
+				                  AND nc.depth = 1))
+				UNION ALL
+				-- every ancestor of the parent is an ancestor of the child
+				SELECT 'missing_path', ap.ancestor, p.descendant, ap.depth + 1, 1
+				FROM node_closure p
+				    JOIN node_closure ap ON ap.descendant = p.ancestor
+				WHERE p.depth = 1
+				  AND ap.depth > 0
+				  AND NOT EXISTS (SELECT 1
+				                  FROM node_closure l
+				                  WHERE l.ancestor = ap.ancestor
+				                    AND l.descendant = p.descendant
+				                    AND l.depth = ap.depth + 1)
+			)
+			SELECT type, ancestor, descendant, depth, count, COUNT(*) OVER () AS total
+			FROM issues
+			ORDER BY type, descendant, ancestor
+			LIMIT $1`
+	rows, err := db.QueryContext(ctx, query, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer pkg.CloseRows(rows)
 
-	var nodeClosures []domain.NodeClosure
+	var issues []domain.NodeClosureIssue
 	for rows.Next() {
-		nodeClosure := domain.NodeClosure{}
-		err := rows.Scan(&nodeClosure.Ancestor, &nodeClosure.Descendant, &nodeClosure.Depth)
+		issue := domain.NodeClosureIssue{}
+		err := rows.Scan(
+			&issue.Type,
+			&issue.Ancestor,
+			&issue.Descendant,
+			&issue.Depth,
+			&issue.Count,
+			&issue.Total,
+		)
 		if err != nil {
 			return nil, err
 		}
-		nodeClosures = append(nodeClosures, nodeClosure)
+		issues = append(issues, issue)
 	}
 
-	return nodeClosures, nil
+	return issues, nil
 }
@@ -4,14 +4,41 @@ import (
 	"context"
 	"database/sql"
 	"github.com/anhsbolic/closure-table-go/model/domain"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/google/uuid"
 )
 
 type NodeRepository interface {
 	Create(ctx context.Context, tx *sql.Tx, node domain.Node) (domain.Node, error)
+	CreateBulk(ctx context.Context, tx *sql.Tx, nodes []domain.Node) error
 	Update(ctx context.Context, tx *sql.Tx, id string, node domain.Node) (domain.Node, error)
 	DeleteByDescendantIds(ctx context.Context, tx *sql.Tx, descendantIds []string) error
 	GetRootList(ctx context.Context, db *sql.DB) ([]domain.Node, error)
-	CheckByID(ctx context.Context, db *sql.DB, id string) (bool, error)
-	DetailByID(ctx context.Context, db *sql.DB, id string) (domain.Node, error)
+	CheckByID(ctx context.Context, db pkg.DBTX, id string) (bool, error)
+	DetailByID(ctx context.Context, db pkg.DBTX, id string) (domain.Node, error)
+	DetailByExternalID(ctx context.Context, db pkg.DBTX, tenant string, externalId string) (domain.Node, error)
+	FindExistingExternalIDs(ctx context.Context, db pkg.DBTX, tenant string, externalIds []string) ([]string, error)
 	GetDescendantList(ctx context.Context, db *sql.DB, nodeId string) ([]domain.Node, error)
+	GetChildList(ctx context.Context, db *sql.DB, nodeId string, limit int, offset int) ([]domain.Node, error)
+	CountChildren(ctx context.Context, db *sql.DB, nodeId string) (int, error)
+	SoftDeleteByDescendantIds(ctx context.Context, tx *sql.Tx, descendantIds []string, deletedAt sql.NullTime) error
+	RestoreByAncestor(ctx context.Context, tx *sql.Tx, ancestorId string, deletedAt sql.NullTime) error
+	DetailDeletedByID(ctx context.Context, db pkg.DBTX, id string) (domain.Node, error)
+	CheckDeletedAncestorByID(ctx context.Context, db pkg.DBTX, id string) (bool, error)
+	GetTrashList(ctx context.Context, db *sql.DB, limit int, offset int) ([]domain.Node, error)
+	CountTrash(ctx context.Context, db *sql.DB) (int, error)
+	FindAllParents(ctx context.Context, tx *sql.Tx) ([]domain.Node, error)
+	UpdateParentID(ctx context.Context, tx *sql.Tx, id string, parentId uuid.NullUUID) error
+	PromoteChildren(ctx context.Context, tx *sql.Tx, id string) error
+	UpdatePosition(ctx context.Context, tx *sql.Tx, id string, position int64) error
+	UpdatePositions(ctx context.Context, tx *sql.Tx, ids []string, positions []int64) error
+	GetSiblingPositions(ctx context.Context, tx *sql.Tx, parentId uuid.NullUUID) ([]domain.Node, error)
+	RenumberSiblings(ctx context.Context, tx *sql.Tx, parentId uuid.NullUUID, gap int64) error
+	FindActiveDescendantIds(ctx context.Context, tx *sql.Tx, ancestorId string) ([]string, error)
+	CreateCopies(ctx context.Context, tx *sql.Tx, rootId string, oldIds []string, newIds []string, parentId uuid.NullUUID, position int64, createdAt sql.NullTime, tenant string) error
+	GetSubtree(ctx context.Context, db *sql.DB, nodeId string, maxDepth int) ([]domain.NodeDescendant, error)
+	StreamSubtree(ctx context.Context, db *sql.DB, nodeId string, fn func(descendant domain.NodeDescendant) error) error
+	CountByIDs(ctx context.Context, db *sql.DB, ids []string) (int, error)
+	FindLowestCommonAncestor(ctx context.Context, db *sql.DB, nodeIds []string) (domain.NodeAncestor, error)
+	GetAncestorListByDescendantIds(ctx context.Context, db *sql.DB, nodeIds []string) ([]domain.NodeAncestor, error)
 }
@@ -3,11 +3,16 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/anhsbolic/closure-table-go/model/domain"
 	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
+	"time"
 )
 
+const createBulkBatchSize = 5000
+
 type NodeRepositoryImpl struct {
 }
 
@@ -17,13 +22,17 @@ func NewNodeRepository() NodeRepository {
 
 func (repository *NodeRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, node domain.Node) (domain.Node, error) {
 	// Save Root Node
-	query := `INSERT INTO nodes (id, title, type, description, created_at) VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	query := `INSERT INTO nodes (id, parent_id, title, type, description, position, created_at, external_id, tenant) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`
 	err := tx.QueryRowContext(ctx, query,
 		node.ID,
+		node.ParentID,
 		node.Title,
 		node.Type,
 		node.Description,
+		node.Position,
 		node.CreatedAt,
+		node.ExternalID,
+		node.Tenant,
 	).Scan(&node.ID)
 
 	if err != nil {
@@ -33,6 +42,56 @@ func (repository *NodeRepositoryImpl) Create(ctx context.Context, tx *sql.Tx, no
 	return node, nil
 }
 
+func (repository *NodeRepositoryImpl) CreateBulk(ctx context.Context, tx *sql.Tx, nodes []domain.Node) error {
+	query := `INSERT INTO nodes (id, parent_id, title, type, description, position, created_at, external_id, tenant)
+			SELECT * FROM unnest($1::uuid[], $2::uuid[], $3::varchar[], $4::varchar[], $5::text[], $6::bigint[], $7::timestamptz[], $8::varchar[], $9::varchar[])`
+
+	for start := 0; start < len(nodes); start += createBulkBatchSize {
+		end := min(start+createBulkBatchSize, len(nodes))
+
+		ids := make([]string, 0, end-start)
+		parentIds := make([]uuid.NullUUID, 0, end-start)
+		titles := make([]string, 0, end-start)
+		types := make([]string, 0, end-start)
+		descriptions := make([]sql.NullString, 0, end-start)
+		positions := make([]int64, 0, end-start)
+		createdAts := make([]sql.NullString, 0, end-start)
+		externalIds := make([]sql.NullString, 0, end-start)
+		tenants := make([]string, 0, end-start)
+		for _, node := range nodes[start:end] {
+			ids = append(ids, node.ID.String())
+			parentIds = append(parentIds, node.ParentID)
+			titles = append(titles, node.Title)
+			types = append(types, node.Type)
+			descriptions = append(descriptions, node.Description)
+			positions = append(positions, node.Position)
+			createdAts = append(createdAts, sql.NullString{
+				String: node.CreatedAt.Time.Format(time.RFC3339Nano),
+				Valid:  node.CreatedAt.Valid,
+			})
+			externalIds = append(externalIds, node.ExternalID)
+			tenants = append(tenants, node.Tenant)
+		}
+
+		_, err := tx.ExecContext(ctx, query,
+			pq.Array(ids),
+			pq.Array(parentIds),
+			pq.Array(titles),
+			pq.Array(types),
+			pq.Array(descriptions),
+			pq.Array(positions),
+			pq.Array(createdAts),
+			pq.Array(externalIds),
+			pq.Array(tenants),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (repository *NodeRepositoryImpl) Update(ctx context.Context, tx *sql.Tx, id string, node domain.Node) (domain.Node, error) {
 	query := `UPDATE nodes SET title = $1, type = $2, description = $3, updated_at = $4 WHERE id = $5`
 	_, err := tx.ExecContext(ctx, query,
@@ -61,16 +120,11 @@ func (repository *NodeRepositoryImpl) DeleteByDescendantIds(ctx context.Context,
 
 func (repository *NodeRepositoryImpl) GetRootList(ctx context.Context, db *sql.DB) ([]domain.Node, error) {
 	// Get Root List
-	query := `SELECT n.id, n.title, n.type, n.description, n.created_at, n.updated_at
+	query := `SELECT n.id, n.parent_id, n.external_id, n.title, n.type, n.description, n.created_at, n.updated_at
 			FROM nodes n
-			    JOIN node_closure nc ON n.id = nc.descendant
-			WHERE nc.ancestor = nc.descendant
-			  AND nc.depth = 0
-			  AND NOT EXISTS (SELECT 1
-			                  FROM node_closure nc2
-			                  WHERE nc2.descendant = nc.descendant
-			                    AND nc2.ancestor != nc.descendant)
-			ORDER BY n.created_at DESC`
+			WHERE n.parent_id IS NULL
+			  AND n.deleted_at IS NULL
+			ORDER BY n.position, n.created_at DESC, n.id`
 	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
@@ -82,6 +136,8 @@ func (repository *NodeRepositoryImpl) GetRootList(ctx context.Context, db *sql.D
 		node := domain.Node{}
 		err := rows.Scan(
 			&node.ID,
+			&node.ParentID,
+			&node.ExternalID,
 			&node.Title,
 			&node.Type,
 			&node.Description,
@@ -97,29 +153,35 @@ func (repository *NodeRepositoryImpl) GetRootList(ctx context.Context, db *sql.D
 	return nodes, nil
 }
 
-func (repository *NodeRepositoryImpl) CheckByID(ctx context.Context, db *sql.DB, id string) (bool, error) {
-	query := `SELECT id FROM nodes WHERE id = $1`
+func (repository *NodeRepositoryImpl) CheckByID(ctx context.Context, db pkg.DBTX, id string) (bool, error) {
+	query := `SELECT id FROM nodes WHERE id = $1 AND deleted_at IS NULL`
 	rows, err := db.QueryContext(ctx, query, id)
 	if err != nil {
 		return false, err
 	}
+	defer pkg.CloseRows(rows)
 
 	return rows.Next(), nil
 }
 
-func (repository *NodeRepositoryImpl) DetailByID(ctx context.Context, db *sql.DB, id string) (domain.Node, error) {
-	query := `SELECT id, title, type, description, created_at, updated_at FROM nodes WHERE id = $1`
+func (repository *NodeRepositoryImpl) DetailByID(ctx context.Context, db pkg.DBTX, id string) (domain.Node, error) {
+	query := `SELECT id, parent_id, external_id, title, type, description, created_at, updated_at FROM nodes WHERE id = $1 AND deleted_at IS NULL`
 	row := db.QueryRowContext(ctx, query, id)
 
 	node := domain.Node{}
 	err := row.Scan(
 		&node.ID,
+		&node.ParentID,
+		&node.ExternalID,
 		&node.Title,
 		&node.Type,
 		&node.Description,
 		&node.CreatedAt,
 		&node.UpdatedAt,
 	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Node{}, nil
+	}
 	if err != nil {
 		return domain.Node{}, err
 	}
@@ -127,13 +189,62 @@ func (repository *NodeRepositoryImpl) DetailByID(ctx context.Context, db *sql.DB
 	return node, nil
 }
 
+func (repository *NodeRepositoryImpl) DetailByExternalID(ctx context.Context, db pkg.DBTX, tenant string, externalId string) (domain.Node, error) {
+	// Trashed nodes are returned too, they still hold their external ID
+	query := `SELECT id, parent_id, external_id, title, type, description, created_at, updated_at, deleted_at FROM nodes WHERE tenant = $1 AND external_id = $2`
+	row := db.QueryRowContext(ctx, query, tenant, externalId)
+
+	node := domain.Node{}
+	err := row.Scan(
+		&node.ID,
+		&node.ParentID,
+		&node.ExternalID,
+		&node.Title,
+		&node.Type,
+		&node.Description,
+		&node.CreatedAt,
+		&node.UpdatedAt,
+		&node.DeletedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Node{}, nil
+	}
+	if err != nil {
+		return domain.Node{}, err
+	}
+
+	return node, nil
+}
+
+func (repository *NodeRepositoryImpl) FindExistingExternalIDs(ctx context.Context, db pkg.DBTX, tenant string, externalIds []string) ([]string, error) {
+	query := `SELECT external_id FROM nodes WHERE tenant = $1 AND external_id = ANY($2)`
+	rows, err := db.QueryContext(ctx, query, tenant, pq.Array(externalIds))
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.CloseRows(rows)
+
+	var existingIds []string
+	for rows.Next() {
+		var externalId string
+		err := rows.Scan(&externalId)
+		if err != nil {
+			return nil, err
+		}
+		existingIds = append(existingIds, externalId)
+	}
+
+	return existingIds, nil
+}
+
 func (repository *NodeRepositoryImpl) GetDescendantList(ctx context.Context, db *sql.DB, nodeId string) ([]domain.Node, error) {
 	// Get Descendant List
-	query := `SELECT n.id, n.title, n.type, n.description, n.created_at, n.updated_at
+	query := `SELECT n.id, n.parent_id, n.external_id, n.title, n.type, n.description, n.created_at, n.updated_at
 			FROM nodes n
 			    JOIN node_closure nc ON n.id = nc.descendant
 			WHERE nc.ancestor = $1
 			  AND nc.depth > 0
+			  AND n.deleted_at IS NULL
 			ORDER BY n.created_at DESC`
 	rows, err := db.QueryContext(ctx, query, nodeId)
 	if err != nil {
@@ -146,11 +257,296 @@ func (repository *NodeRepositoryImpl) GetDescendantList(ctx context.Context, db
 		node := domain.Node{}
 		err := rows.Scan(
 			&node.ID,
+			&node.ParentID,
+			&node.ExternalID,
+			&node.Title,
+			&node.Type,
+			&node.Description,
+			&node.CreatedAt,
+			&node.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+func (repository *NodeRepositoryImpl) GetChildList(ctx context.Context, db *sql.DB, nodeId string, limit int, offset int) ([]domain.Node, error) {
+	// Get Direct Children
+	query := `SELECT n.id, n.parent_id, n.external_id, n.title, n.type, n.description, n.created_at, n.updated_at
+			FROM nodes n
+			WHERE n.parent_id = $1
+			  AND n.deleted_at IS NULL
+			ORDER BY n.position, n.created_at DESC, n.id
+			LIMIT $2 OFFSET $3`
+	rows, err := db.QueryContext(ctx, query, nodeId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.CloseRows(rows)
+
+	var nodes []domain.Node
+	for rows.Next() {
+		node := domain.Node{}
+		err := rows.Scan(
+			&node.ID,
+			&node.ParentID,
+			&node.ExternalID,
+			&node.Title,
+			&node.Type,
+			&node.Description,
+			&node.CreatedAt,
+			&node.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+func (repository *NodeRepositoryImpl) CountChildren(ctx context.Context, db *sql.DB, nodeId string) (int, error) {
+	query := `SELECT COUNT(*) FROM nodes WHERE parent_id = $1 AND deleted_at IS NULL`
+
+	var total int
+	err := db.QueryRowContext(ctx, query, nodeId).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (repository *NodeRepositoryImpl) GetAncestorListByDescendantIds(ctx context.Context, db *sql.DB, nodeIds []string) ([]domain.NodeAncestor, error) {
+	// Get Ancestor Paths, Ordered From Root To Node
+	query := `SELECT nc.descendant, n.id, n.parent_id, n.title, n.type, n.description, n.created_at, n.updated_at,
+			       MAX(nc.depth) OVER (PARTITION BY nc.descendant) - nc.depth AS depth
+			FROM node_closure nc
+			    JOIN nodes n ON n.id = nc.ancestor
+			    JOIN nodes d ON d.id = nc.descendant
+			WHERE nc.descendant = ANY($1)
+			  AND d.deleted_at IS NULL
+			ORDER BY nc.descendant, nc.depth DESC`
+	rows, err := db.QueryContext(ctx, query, pq.Array(nodeIds))
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.CloseRows(rows)
+
+	var ancestors []domain.NodeAncestor
+	for rows.Next() {
+		ancestor := domain.NodeAncestor{}
+		err := rows.Scan(
+			&ancestor.Descendant,
+			&ancestor.Node.ID,
+			&ancestor.Node.ParentID,
+			&ancestor.Node.Title,
+			&ancestor.Node.Type,
+			&ancestor.Node.Description,
+			&ancestor.Node.CreatedAt,
+			&ancestor.Node.UpdatedAt,
+			&ancestor.Depth,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ancestors = append(ancestors, ancestor)
+	}
+
+	return ancestors, nil
+}
+
+func (repository *NodeRepositoryImpl) CountByIDs(ctx context.Context, db *sql.DB, ids []string) (int, error) {
+	query := `SELECT COUNT(*) FROM nodes WHERE id = ANY($1) AND deleted_at IS NULL`
+
+	var total int
+	err := db.QueryRowContext(ctx, query, pq.Array(ids)).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (repository *NodeRepositoryImpl) FindLowestCommonAncestor(ctx context.Context, db *sql.DB, nodeIds []string) (domain.NodeAncestor, error) {
+	// Shared ancestors lie on one chain, the deepest is the closest to the nodes, depth is counted from root
+	query := `SELECT n.id, n.parent_id, n.title, n.type, n.description, n.created_at, n.updated_at,
+			       (SELECT MAX(r.depth) FROM node_closure r WHERE r.descendant = n.id) AS depth
+			FROM node_closure nc
+			    JOIN nodes n ON n.id = nc.ancestor
+			WHERE nc.descendant = ANY($1)
+			GROUP BY n.id
+			HAVING COUNT(DISTINCT nc.descendant) = $2
+			ORDER BY MIN(nc.depth)
+			LIMIT 1`
+	row := db.QueryRowContext(ctx, query, pq.Array(nodeIds), len(nodeIds))
+
+	ancestor := domain.NodeAncestor{}
+	err := row.Scan(
+		&ancestor.Node.ID,
+		&ancestor.Node.ParentID,
+		&ancestor.Node.Title,
+		&ancestor.Node.Type,
+		&ancestor.Node.Description,
+		&ancestor.Node.CreatedAt,
+		&ancestor.Node.UpdatedAt,
+		&ancestor.Depth,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.NodeAncestor{}, nil
+	}
+	if err != nil {
+		return domain.NodeAncestor{}, err
+	}
+
+	return ancestor, nil
+}
+
+func (repository *NodeRepositoryImpl) GetSubtree(ctx context.Context, db *sql.DB, nodeId string, maxDepth int) ([]domain.NodeDescendant, error) {
+	// Get Node With Descendants Up To Max Depth, Parents Before Children
+	query := `SELECT n.id, n.parent_id, n.title, n.type, n.description, n.created_at, n.updated_at,
+			       nc.depth,
+			       EXISTS (SELECT 1
+			               FROM nodes c
+			               WHERE c.parent_id = n.id
+			                 AND c.deleted_at IS NULL) AS has_children
+			FROM nodes n
+			    JOIN node_closure nc ON n.id = nc.descendant
+			WHERE nc.ancestor = $1
+			  AND nc.depth <= $2
+			  AND n.deleted_at IS NULL
+			ORDER BY nc.depth, n.position, n.created_at DESC, n.id`
+	rows, err := db.QueryContext(ctx, query, nodeId, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.CloseRows(rows)
+
+	var descendants []domain.NodeDescendant
+	for rows.Next() {
+		descendant := domain.NodeDescendant{}
+		err := rows.Scan(
+			&descendant.Node.ID,
+			&descendant.Node.ParentID,
+			&descendant.Node.Title,
+			&descendant.Node.Type,
+			&descendant.Node.Description,
+			&descendant.Node.CreatedAt,
+			&descendant.Node.UpdatedAt,
+			&descendant.Depth,
+			&descendant.HasChildren,
+		)
+		if err != nil {
+			return nil, err
+		}
+		descendants = append(descendants, descendant)
+	}
+
+	return descendants, nil
+}
+
+func (repository *NodeRepositoryImpl) SoftDeleteByDescendantIds(ctx context.Context, tx *sql.Tx, descendantIds []string, deletedAt sql.NullTime) error {
+	query := `UPDATE nodes SET deleted_at = $1 WHERE id = ANY($2) AND deleted_at IS NULL`
+	_, err := tx.ExecContext(ctx, query, deletedAt, pq.Array(descendantIds))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *NodeRepositoryImpl) RestoreByAncestor(ctx context.Context, tx *sql.Tx, ancestorId string, deletedAt sql.NullTime) error {
+	// Restore only the nodes trashed together with the ancestor
+	query := `UPDATE nodes
+			SET deleted_at = NULL
+			WHERE deleted_at = $2
+			  AND id IN (SELECT descendant FROM node_closure WHERE ancestor = $1)`
+	_, err := tx.ExecContext(ctx, query, ancestorId, deletedAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *NodeRepositoryImpl) DetailDeletedByID(ctx context.Context, db pkg.DBTX, id string) (domain.Node, error) {
+	query := `SELECT id, parent_id, title, type, description, created_at, updated_at, deleted_at FROM nodes WHERE id = $1 AND deleted_at IS NOT NULL`
+	row := db.QueryRowContext(ctx, query, id)
+
+	node := domain.Node{}
+	err := row.Scan(
+		&node.ID,
+		&node.ParentID,
+		&node.Title,
+		&node.Type,
+		&node.Description,
+		&node.CreatedAt,
+		&node.UpdatedAt,
+		&node.DeletedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Node{}, nil
+	}
+	if err != nil {
+		return domain.Node{}, err
+	}
+
+	return node, nil
+}
+
+func (repository *NodeRepositoryImpl) CheckDeletedAncestorByID(ctx context.Context, db pkg.DBTX, id string) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1
+			               FROM node_closure nc
+			                   JOIN nodes n ON n.id = nc.ancestor
+			               WHERE nc.descendant = $1
+			                 AND nc.depth > 0
+			                 AND n.deleted_at IS NOT NULL)`
+
+	var isExist bool
+	err := db.QueryRowContext(ctx, query, id).Scan(&isExist)
+	if err != nil {
+		return false, err
+	}
+
+	return isExist, nil
+}
+
+func (repository *NodeRepositoryImpl) GetTrashList(ctx context.Context, db *sql.DB, limit int, offset int) ([]domain.Node, error) {
+	// Get Trashed Nodes Whose Parent Was Not Trashed Together With Them
+	query := `SELECT n.id, n.parent_id, n.title, n.type, n.description, n.created_at, n.updated_at, n.deleted_at
+			FROM nodes n
+			WHERE n.deleted_at IS NOT NULL
+			  AND NOT EXISTS (SELECT 1
+			                  FROM node_closure nc
+			                      JOIN nodes p ON p.id = nc.ancestor
+			                  WHERE nc.descendant = n.id
+			                    AND nc.depth = 1
+			                    AND p.deleted_at = n.deleted_at)
+			ORDER BY n.deleted_at DESC, n.id
+			LIMIT $1 OFFSET $2`
+	rows, err := db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.CloseRows(rows)
+
+	var nodes []domain.Node
+	for rows.Next() {
+		node := domain.Node{}
+		err := rows.Scan(
+			&node.ID,
+			&node.ParentID,
 			&node.Title,
 			&node.Type,
 			&node.Description,
 			&node.CreatedAt,
 			&node.UpdatedAt,
+			&node.DeletedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -160,3 +556,230 @@ func (repository *NodeRepositoryImpl) GetDescendantList(ctx context.Context, db
 
 	return nodes, nil
 }
+
+func (repository *NodeRepositoryImpl) CountTrash(ctx context.Context, db *sql.DB) (int, error) {
+	query := `SELECT COUNT(*)
+			FROM nodes n
+			WHERE n.deleted_at IS NOT NULL
+			  AND NOT EXISTS (SELECT 1
+			                  FROM node_closure nc
+			                      JOIN nodes p ON p.id = nc.ancestor
+			                  WHERE nc.descendant = n.id
+			                    AND nc.depth = 1
+			                    AND p.deleted_at = n.deleted_at)`
+
+	var total int
+	err := db.QueryRowContext(ctx, query).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (repository *NodeRepositoryImpl) FindAllParents(ctx context.Context, tx *sql.Tx) ([]domain.Node, error) {
+	query := `SELECT id, parent_id FROM nodes ORDER BY id`
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.CloseRows(rows)
+
+	var nodes []domain.Node
+	for rows.Next() {
+		node := domain.Node{}
+		err := rows.Scan(&node.ID, &node.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+func (repository *NodeRepositoryImpl) UpdateParentID(ctx context.Context, tx *sql.Tx, id string, parentId uuid.NullUUID) error {
+	query := `UPDATE nodes SET parent_id = $1 WHERE id = $2`
+	_, err := tx.ExecContext(ctx, query, parentId, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *NodeRepositoryImpl) PromoteChildren(ctx context.Context, tx *sql.Tx, id string) error {
+	// Children take over the node's parent, the node keeps its own so it is restored in place
+	query := `UPDATE nodes SET parent_id = (SELECT parent_id FROM nodes WHERE id = $1) WHERE parent_id = $1`
+	_, err := tx.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *NodeRepositoryImpl) UpdatePosition(ctx context.Context, tx *sql.Tx, id string, position int64) error {
+	query := `UPDATE nodes SET position = $1 WHERE id = $2`
+	_, err := tx.ExecContext(ctx, query, position, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *NodeRepositoryImpl) UpdatePositions(ctx context.Context, tx *sql.Tx, ids []string, positions []int64) error {
+	query := `UPDATE nodes n
+			SET position = p.position
+			FROM UNNEST($1::uuid[], $2::bigint[]) AS p(id, position)
+			WHERE n.id = p.id`
+	_, err := tx.ExecContext(ctx, query, pq.Array(ids), pq.Array(positions))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *NodeRepositoryImpl) GetSiblingPositions(ctx context.Context, tx *sql.Tx, parentId uuid.NullUUID) ([]domain.Node, error) {
+	// Get Siblings In Display Order
+	query := `SELECT id, position
+			FROM nodes
+			WHERE (parent_id = $1 OR ($1::uuid IS NULL AND parent_id IS NULL))
+			  AND deleted_at IS NULL
+			ORDER BY position, created_at DESC, id`
+	rows, err := tx.QueryContext(ctx, query, parentId)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.CloseRows(rows)
+
+	var nodes []domain.Node
+	for rows.Next() {
+		node := domain.Node{}
+		err := rows.Scan(&node.ID, &node.Position)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
+
+func (repository *NodeRepositoryImpl) RenumberSiblings(ctx context.Context, tx *sql.Tx, parentId uuid.NullUUID, gap int64) error {
+	// Spread Siblings Evenly, Keeping Their Order
+	query := `UPDATE nodes n
+			SET position = ranked.rn * $2
+			FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY position, created_at DESC, id) AS rn
+			      FROM nodes
+			      WHERE (parent_id = $1 OR ($1::uuid IS NULL AND parent_id IS NULL))
+			        AND deleted_at IS NULL) ranked
+			WHERE ranked.id = n.id`
+	_, err := tx.ExecContext(ctx, query, parentId, gap)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *NodeRepositoryImpl) FindActiveDescendantIds(ctx context.Context, tx *sql.Tx, ancestorId string) ([]string, error) {
+	query := `SELECT nc.descendant
+			FROM node_closure nc
+			    JOIN nodes n ON n.id = nc.descendant
+			WHERE nc.ancestor = $1
+			  AND n.deleted_at IS NULL`
+	rows, err := tx.QueryContext(ctx, query, ancestorId)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.CloseRows(rows)
+
+	var descendantIds []string
+	for rows.Next() {
+		var descendantID string
+		err := rows.Scan(&descendantID)
+		if err != nil {
+			return nil, err
+		}
+		descendantIds = append(descendantIds, descendantID)
+	}
+
+	return descendantIds, nil
+}
+
+func (repository *NodeRepositoryImpl) CreateCopies(ctx context.Context, tx *sql.Tx, rootId string, oldIds []string, newIds []string, parentId uuid.NullUUID, position int64, createdAt sql.NullTime, tenant string) error {
+	// Copy Nodes, The Copied Root Goes Under parentId At position And Every Copy Belongs To tenant
+	query := `INSERT INTO nodes (id, parent_id, title, type, description, position, created_at, tenant)
+			SELECT m.new_id,
+			       CASE WHEN n.id = $3 THEN $4::uuid ELSE pm.new_id END,
+			       n.title,
+			       n.type,
+			       n.description,
+			       CASE WHEN n.id = $3 THEN $5 ELSE n.position END,
+			       $6,
+			       $7
+			FROM unnest($1::uuid[], $2::uuid[]) AS m(old_id, new_id)
+			    JOIN nodes n ON n.id = m.old_id
+			    LEFT JOIN unnest($1::uuid[], $2::uuid[]) AS pm(old_id, new_id) ON pm.old_id = n.parent_id`
+	_, err := tx.ExecContext(ctx, query, pq.Array(oldIds), pq.Array(newIds), rootId, parentId, position, createdAt, tenant)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *NodeRepositoryImpl) StreamSubtree(ctx context.Context, db *sql.DB, nodeId string, fn func(descendant domain.NodeDescendant) error) error {
+	// Walk Subtree Depth-First, Siblings In Display Order
+	query := `WITH RECURSIVE members AS (SELECT n.id, n.parent_id, n.title, n.type, n.description, n.created_at, n.updated_at,
+			                                   nc.depth,
+			                                   ROW_NUMBER() OVER (PARTITION BY n.parent_id
+			                                                      ORDER BY n.position, n.created_at DESC, n.id) AS rn
+			                            FROM nodes n
+			                                JOIN node_closure nc ON n.id = nc.descendant
+			                            WHERE nc.ancestor = $1
+			                              AND n.deleted_at IS NULL),
+			               paths AS (SELECT m.id, ARRAY [m.rn] AS path
+			                         FROM members m
+			                         WHERE m.depth = 0
+			                         UNION ALL
+			                         SELECT c.id, p.path || c.rn
+			                         FROM paths p
+			                             JOIN members c ON c.parent_id = p.id)
+			SELECT m.id, m.parent_id, m.title, m.type, m.description, m.created_at, m.updated_at, m.depth
+			FROM members m
+			    JOIN paths p ON p.id = m.id
+			ORDER BY p.path`
+	rows, err := db.QueryContext(ctx, query, nodeId)
+	if err != nil {
+		return err
+	}
+	defer pkg.CloseRows(rows)
+
+	for rows.Next() {
+		descendant := domain.NodeDescendant{}
+		err := rows.Scan(
+			&descendant.Node.ID,
+			&descendant.Node.ParentID,
+			&descendant.Node.Title,
+			&descendant.Node.Type,
+			&descendant.Node.Description,
+			&descendant.Node.CreatedAt,
+			&descendant.Node.UpdatedAt,
+			&descendant.Depth,
+		)
+		if err != nil {
+			return err
+		}
+
+		err = fn(descendant)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,42 @@
+package routes
+
+import (
+	"database/sql"
+	"github.com/anhsbolic/closure-table-go/controller"
+	"github.com/anhsbolic/closure-table-go/middleware"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/anhsbolic/closure-table-go/repository"
+	"github.com/anhsbolic/closure-table-go/service"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+func InitAdminRoutes(server *fiber.App, db *sql.DB, rateLimiter pkg.RateLimiter, validate *validator.Validate) {
+	// Setup Closure API
+	nodeRepository := repository.NewNodeRepository()
+	nodeClosureRepository := repository.NewNodeClosureRepository()
+	closureService := service.NewClosureService(nodeRepository, nodeClosureRepository, db)
+	closureController := controller.NewClosureController(closureService)
+
+	// Setup Api Key API
+	apiKeyRepository := repository.NewApiKeyRepository()
+	apiKeyService := service.NewApiKeyService(apiKeyRepository, db, validate)
+	apiKeyController := controller.NewApiKeyController(apiKeyService)
+
+	// Setup Rate Limits
+	readLimit := middleware.NewRateLimitMiddleware(rateLimiter, middleware.RateLimitRead)
+	writeLimit := middleware.NewRateLimitMiddleware(rateLimiter, middleware.RateLimitWrite)
+
+	// Set Routes
+	v1AdminAPI := server.Group("/v1/admin", middleware.RequireScope(pkg.ScopeAdmin))
+
+	v1AdminClosureAPI := v1AdminAPI.Group("/closure")
+	v1AdminClosureAPI.Get("/verify", readLimit, closureController.Verify)
+	v1AdminClosureAPI.Post("/rebuild", writeLimit, closureController.Rebuild)
+
+	v1AdminApiKeysAPI := v1AdminAPI.Group("/api-keys")
+	v1AdminApiKeysAPI.Post("/", writeLimit, apiKeyController.Create)
+	v1AdminApiKeysAPI.Get("/", readLimit, apiKeyController.List)
+	v1AdminApiKeysAPI.Post("/:apiKeyId/rotate", writeLimit, apiKeyController.Rotate)
+	v1AdminApiKeysAPI.Delete("/:apiKeyId", writeLimit, apiKeyController.Revoke)
+}
@@ -3,26 +3,56 @@ package routes
 import (
 	"database/sql"
 	"github.com/anhsbolic/closure-table-go/controller"
+	"github.com/anhsbolic/closure-table-go/middleware"
+	"github.com/anhsbolic/closure-table-go/pkg"
 	"github.com/anhsbolic/closure-table-go/repository"
 	"github.com/anhsbolic/closure-table-go/service"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
-func InitNodeRoutes(server *fiber.App, db *sql.DB, validate *validator.Validate) {
+func InitNodeRoutes(server *fiber.App, db *sql.DB, cache pkg.Cache, rateLimiter pkg.RateLimiter, validate *validator.Validate) {
 	// Setup Node API
 	nodeRepository := repository.NewNodeRepository()
 	nodeClosureRepository := repository.NewNodeClosureRepository()
-	nodeService := service.NewNodeService(nodeRepository, nodeClosureRepository, db, validate)
+	nodeService := service.NewNodeService(nodeRepository, nodeClosureRepository, db, cache, validate)
 	nodeController := controller.NewNodeController(nodeService)
 
+	// Setup Idempotency-Key Support For Retried Mutations
+	idempotency := middleware.NewIdempotencyMiddleware(db)
+
+	// Setup Rate Limits And Scopes, POST /ancestors is a read
+	readLimit := middleware.NewRateLimitMiddleware(rateLimiter, middleware.RateLimitRead)
+	writeLimit := middleware.NewRateLimitMiddleware(rateLimiter, middleware.RateLimitWrite)
+	read := middleware.RequireScope(pkg.ScopeNodesRead)
+	write := middleware.RequireScope(pkg.ScopeNodesWrite)
+
 	// Set Routes
 	v1NodesAPI := server.Group("/v1/nodes")
-	v1NodesAPI.Post("/", nodeController.Create)
-	v1NodesAPI.Get("/", nodeController.RootList)
-	v1NodesAPI.Get("/:nodeId", nodeController.DetailNode)
-	v1NodesAPI.Put("/:nodeId", nodeController.UpdateNode)
-	v1NodesAPI.Delete("/:nodeId", nodeController.DeleteNode)
-	v1NodesAPI.Get("/:nodeId/descendants", nodeController.DescendantList)
-	v1NodesAPI.Put("/:nodeId/move", nodeController.MoveNode)
+	v1NodesAPI.Post("/", writeLimit, write, idempotency, nodeController.Create)
+	v1NodesAPI.Post("/ancestors", readLimit, read, nodeController.AncestorListBulk)
+	v1NodesAPI.Post("/import", writeLimit, write, nodeController.ImportNodes)
+	v1NodesAPI.Post("/import/csv", writeLimit, write, nodeController.ImportCSV)
+	v1NodesAPI.Post("/import/:format", writeLimit, write, nodeController.ImportOutline)
+	v1NodesAPI.Get("/", readLimit, read, nodeController.RootList)
+	v1NodesAPI.Get("/lca", readLimit, read, nodeController.LowestCommonAncestor)
+	v1NodesAPI.Get("/by-external/:externalId", readLimit, read, nodeController.DetailByExternalID)
+	v1NodesAPI.Put("/by-external/:externalId", writeLimit, write, nodeController.UpsertByExternalID)
+	v1NodesAPI.Get("/:nodeId", readLimit, read, nodeController.DetailNode)
+	v1NodesAPI.Put("/:nodeId", writeLimit, write, nodeController.UpdateNode)
+	v1NodesAPI.Delete("/:nodeId", writeLimit, write, idempotency, nodeController.DeleteNode)
+	v1NodesAPI.Get("/:nodeId/descendants", readLimit, read, nodeController.DescendantList)
+	v1NodesAPI.Get("/:nodeId/children", readLimit, read, nodeController.ChildList)
+	v1NodesAPI.Get("/:nodeId/ancestors", readLimit, read, nodeController.AncestorList)
+	v1NodesAPI.Get("/:nodeId/path-to/:targetId", readLimit, read, nodeController.PathTo)
+	v1NodesAPI.Get("/:nodeId/tree", readLimit, read, nodeController.TreeNode)
+	v1NodesAPI.Get("/:nodeId/export", readLimit, read, nodeController.ExportNode)
+	v1NodesAPI.Post("/:nodeId/restore", writeLimit, write, nodeController.RestoreNode)
+
+	v1TrashAPI := server.Group("/v1/trash")
+	v1TrashAPI.Get("/", readLimit, read, nodeController.TrashList)
+	v1TrashAPI.Delete("/:nodeId", writeLimit, write, nodeController.PurgeNode)
+	v1NodesAPI.Put("/:nodeId/move", writeLimit, write, idempotency, nodeController.MoveNode)
+	v1NodesAPI.Put("/:nodeId/reorder", writeLimit, write, nodeController.ReorderNode)
+	v1NodesAPI.Post("/:nodeId/copy", writeLimit, write, idempotency, nodeController.CopyNode)
 }
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+	"github.com/anhsbolic/closure-table-go/model/dto"
+)
+
+type ApiKeyService interface {
+	Create(ctx context.Context, request dto.ApiKeyCreateRequest) (dto.ApiKeyCreatedResponse, error)
+	List(ctx context.Context) ([]dto.ApiKeyResponse, error)
+	Rotate(ctx context.Context, apiKeyId string) (dto.ApiKeyCreatedResponse, error)
+	Revoke(ctx context.Context, apiKeyId string) (dto.ApiKeyResponse, error)
+}
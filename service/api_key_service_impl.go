@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"github.com/anhsbolic/closure-table-go/model/dto"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/anhsbolic/closure-table-go/repository"
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"slices"
+	"time"
+)
+
+type ApiKeyServiceImpl struct {
+	ApiKeyRepository repository.ApiKeyRepository
+	DB               *sql.DB
+	Validate         *validator.Validate
+}
+
+func NewApiKeyService(apiKeyRepository repository.ApiKeyRepository, db *sql.DB, validate *validator.Validate) ApiKeyService {
+	return &ApiKeyServiceImpl{
+		ApiKeyRepository: apiKeyRepository,
+		DB:               db,
+		Validate:         validate,
+	}
+}
+
+func (service *ApiKeyServiceImpl) Create(ctx context.Context, request dto.ApiKeyCreateRequest) (dto.ApiKeyCreatedResponse, error) {
+	// Validate request
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return dto.ApiKeyCreatedResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	now := time.Now()
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(now) {
+		return dto.ApiKeyCreatedResponse{}, fiber.NewError(fiber.StatusBadRequest, "expires_at must be in the future")
+	}
+
+	// Generate Key
+	id := uuid.New()
+	key, err := pkg.GenerateApiKey(id)
+	if err != nil {
+		return dto.ApiKeyCreatedResponse{}, err
+	}
+
+	// Save Key Hash
+	scopes := slices.Compact(slices.Sorted(slices.Values(request.Scopes)))
+	expiresAt := sql.NullTime{Valid: false}
+	if request.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: *request.ExpiresAt, Valid: true}
+	}
+	apiKey, err := service.ApiKeyRepository.Create(ctx, service.DB, domain.ApiKey{
+		ID:        id,
+		Name:      request.Name,
+		KeyPrefix: pkg.ApiKeyDisplayPrefix(id),
+		KeyHash:   pkg.HashApiKey(key),
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		return dto.ApiKeyCreatedResponse{}, err
+	}
+
+	// return response
+	return dto.ApiKeyCreatedResponse{
+		ApiKeyResponse: dto.ToApiKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+func (service *ApiKeyServiceImpl) List(ctx context.Context) ([]dto.ApiKeyResponse, error) {
+	// Get Api Key List
+	apiKeys, err := service.ApiKeyRepository.GetList(ctx, service.DB)
+	if err != nil {
+		return nil, err
+	}
+
+	// return response
+	return dto.ToApiKeyListResponse(apiKeys), nil
+}
+
+func (service *ApiKeyServiceImpl) Rotate(ctx context.Context, apiKeyId string) (dto.ApiKeyCreatedResponse, error) {
+	// Check Api Key By ID
+	apiKey, err := service.detailApiKey(ctx, apiKeyId)
+	if err != nil {
+		return dto.ApiKeyCreatedResponse{}, err
+	}
+	if apiKey.RevokedAt.Valid {
+		return dto.ApiKeyCreatedResponse{}, errApiKeyRevoked()
+	}
+
+	// Replace Key, the old key stops working immediately
+	key, err := pkg.GenerateApiKey(apiKey.ID)
+	if err != nil {
+		return dto.ApiKeyCreatedResponse{}, err
+	}
+	apiKey.KeyHash = pkg.HashApiKey(key)
+	apiKey.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	isUpdated, err := service.ApiKeyRepository.UpdateKeyHash(ctx, service.DB, apiKeyId, apiKey.KeyHash, apiKey.UpdatedAt)
+	if err != nil {
+		return dto.ApiKeyCreatedResponse{}, err
+	}
+	if !isUpdated {
+		return dto.ApiKeyCreatedResponse{}, errApiKeyRevoked()
+	}
+
+	// return response
+	return dto.ApiKeyCreatedResponse{
+		ApiKeyResponse: dto.ToApiKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+func (service *ApiKeyServiceImpl) Revoke(ctx context.Context, apiKeyId string) (dto.ApiKeyResponse, error) {
+	// Check Api Key By ID
+	apiKey, err := service.detailApiKey(ctx, apiKeyId)
+	if err != nil {
+		return dto.ApiKeyResponse{}, err
+	}
+
+	// Revoke Api Key, revoking twice keeps the first revocation time
+	if !apiKey.RevokedAt.Valid {
+		apiKey.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		apiKey.UpdatedAt = apiKey.RevokedAt
+		err = service.ApiKeyRepository.Revoke(ctx, service.DB, apiKeyId, apiKey.RevokedAt)
+		if err != nil {
+			return dto.ApiKeyResponse{}, err
+		}
+	}
+
+	// return response
+	return dto.ToApiKeyResponse(apiKey), nil
+}
+
+// detailApiKey returns the API key or a not found error
+func (service *ApiKeyServiceImpl) detailApiKey(ctx context.Context, apiKeyId string) (domain.ApiKey, error) {
+	err := service.Validate.Var(apiKeyId, "uuid")
+	if err != nil {
+		return domain.ApiKey{}, fiber.ErrNotFound
+	}
+
+	apiKey, err := service.ApiKeyRepository.DetailByID(ctx, service.DB, apiKeyId)
+	if err != nil {
+		return domain.ApiKey{}, err
+	}
+	if apiKey.ID == uuid.Nil {
+		return domain.ApiKey{}, fiber.ErrNotFound
+	}
+
+	return apiKey, nil
+}
+
+// errApiKeyRevoked reports a revoked key, revoked keys cannot be rotated back to life
+func errApiKeyRevoked() error {
+	return pkg.NewAppError(
+		fiber.StatusConflict,
+		"API_KEY_REVOKED",
+		"API key has been revoked",
+	)
+}
@@ -0,0 +1,11 @@
+package service
+
+import (
+	"context"
+	"github.com/anhsbolic/closure-table-go/model/dto"
+)
+
+type ClosureService interface {
+	Verify(ctx context.Context) (dto.ClosureVerifyResponse, error)
+	Rebuild(ctx context.Context) (dto.ClosureRebuildResponse, error)
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"github.com/anhsbolic/closure-table-go/model/domain"
+	"github.com/anhsbolic/closure-table-go/model/dto"
+	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/anhsbolic/closure-table-go/repository"
+	"github.com/google/uuid"
+)
+
+const maxReportedClosureIssues = 1000
+
+type ClosureServiceImpl struct {
+	NodeRepository        repository.NodeRepository
+	NodeClosureRepository repository.NodeClosureRepository
+	DB                    *sql.DB
+	TreeTxOptions         *sql.TxOptions
+}
+
+func NewClosureService(
+	nodeRepository repository.NodeRepository,
+	nodeClosureRepository repository.NodeClosureRepository,
+	db *sql.DB,
+) ClosureService {
+	return &ClosureServiceImpl{
+		NodeRepository:        nodeRepository,
+		NodeClosureRepository: nodeClosureRepository,
+		DB:                    db,
+		TreeTxOptions:         &sql.TxOptions{Isolation: sql.LevelSerializable},
+	}
+}
+
+func (service *ClosureServiceImpl) Verify(ctx context.Context) (dto.ClosureVerifyResponse, error) {
+	// Find Integrity Issues
+	issues, err := service.NodeClosureRepository.FindIntegrityIssues(ctx, service.DB, maxReportedClosureIssues)
+	if err != nil {
+		return dto.ClosureVerifyResponse{}, err
+	}
+
+	// return response
+	return dto.ToClosureVerifyResponse(issues), nil
+}
+
+func (service *ClosureServiceImpl) Rebuild(ctx context.Context) (dto.ClosureRebuildResponse, error) {
+	var response dto.ClosureRebuildResponse
+
+	// Run in serializable transaction, retried on conflict
+	err := pkg.WithRetryTx(ctx, service.DB, service.TreeTxOptions, func(tx *sql.Tx) error {
+		// Get Canonical Parent Of Every Node
+		nodes, err := service.NodeRepository.FindAllParents(ctx, tx)
+		if err != nil {
+			return err
+		}
+		ids := make([]uuid.UUID, 0, len(nodes))
+		parents := map[uuid.UUID]uuid.UUID{}
+		for _, node := range nodes {
+			ids = append(ids, node.ID)
+			if node.ParentID.Valid {
+				parents[node.ID] = node.ParentID.UUID
+			}
+		}
+
+		// Build Closures From Parent Chains
+		closures, detachedIds := buildClosures(ids, parents)
+
+		// Detach Nodes Closing A Cycle
+		for _, detachedId := range detachedIds {
+			err = service.NodeRepository.UpdateParentID(ctx, tx, detachedId.String(), uuid.NullUUID{})
+			if err != nil {
+				return err
+			}
+		}
+
+		// Replace Node Closures
+		err = service.NodeClosureRepository.DeleteAll(ctx, tx)
+		if err != nil {
+			return err
+		}
+		err = service.NodeClosureRepository.SaveBulk(ctx, tx, closures)
+		if err != nil {
+			return err
+		}
+
+		response = dto.ClosureRebuildResponse{
+			NodeCount:       len(ids),
+			ClosureCount:    len(closures),
+			DetachedNodeIDs: detachedIds,
+		}
+		return nil
+	})
+	if err != nil {
+		return dto.ClosureRebuildResponse{}, err
+	}
+
+	// return response
+	return response, nil
+}
+
+// buildClosures walks every node up to its root and emits one path per ancestor,
+// a parent link closing a cycle is dropped and its node becomes a root
+func buildClosures(ids []uuid.UUID, parents map[uuid.UUID]uuid.UUID) ([]domain.NodeClosure, []uuid.UUID) {
+	chains := map[uuid.UUID][]uuid.UUID{}
+	detachedIds := []uuid.UUID{}
+
+	for _, id := range ids {
+		// Walk up until a resolved node, a root or a cycle
+		var walk []uuid.UUID
+		onWalk := map[uuid.UUID]bool{}
+		current := id
+		for {
+			if _, ok := chains[current]; ok {
+				break
+			}
+			walk = append(walk, current)
+			onWalk[current] = true
+
+			parent, ok := parents[current]
+			if !ok {
+				break
+			}
+			if onWalk[parent] {
+				delete(parents, current)
+				detachedIds = append(detachedIds, current)
+				break
+			}
+			current = parent
+		}
+
+		// Resolve chains from the top of the walk down
+		for i := len(walk) - 1; i >= 0; i-- {
+			node := walk[i]
+			chain := []uuid.UUID{node}
+			if parent, ok := parents[node]; ok {
+				chain = append(chain, chains[parent]...)
+			}
+			chains[node] = chain
+		}
+	}
+
+	var closures []domain.NodeClosure
+	for _, id := range ids {
+		for depth, ancestor := range chains[id] {
+			closures = append(closures, domain.NodeClosure{
+				Ancestor:   ancestor,
+				Descendant: id,
+				Depth:      depth,
+			})
+		}
+	}
+
+	return closures, detachedIds
+}
@@ -3,14 +3,32 @@ package service
 import (
 	"context"
 	"github.com/anhsbolic/closure-table-go/model/dto"
+	"io"
 )
 
 type NodeService interface {
 	Create(ctx context.Context, request dto.NodeCreateRequest) (dto.NodeCreatedResponse, error)
 	RootList(ctx context.Context) ([]dto.NodeResponse, error)
 	DetailNode(ctx context.Context, nodeId string) (dto.NodeResponse, error)
+	DetailByExternalID(ctx context.Context, externalId string) (dto.NodeResponse, error)
+	UpsertByExternalID(ctx context.Context, externalId string, request dto.NodeUpsertRequest) (dto.NodeResponse, bool, error)
 	UpdateNode(ctx context.Context, nodeId string, request dto.NodeUpdateRequest) (dto.NodeResponse, error)
-	DeleteNode(ctx context.Context, nodeId string) error
+	DeleteNode(ctx context.Context, nodeId string, request dto.NodeDeleteRequest) error
 	DescendantList(ctx context.Context, nodeId string) ([]dto.NodeResponse, error)
 	MoveNode(ctx context.Context, nodeId string, request dto.NodeMoveRequest) error
+	ImportNodes(ctx context.Context, request dto.NodeImportRequest) (dto.NodeImportedResponse, error)
+	ImportOutline(ctx context.Context, request dto.NodeOutlineImportRequest, body io.Reader) (dto.NodeImportedResponse, error)
+	ImportCSV(ctx context.Context, request dto.NodeCSVImportRequest, body io.Reader) (dto.NodeCSVImportedResponse, error)
+	ExportNode(ctx context.Context, nodeId string, request dto.NodeExportRequest) (func(w io.Writer) error, error)
+	CopyNode(ctx context.Context, nodeId string, request dto.NodeCopyRequest) (dto.NodeCopiedResponse, error)
+	ReorderNode(ctx context.Context, nodeId string, request dto.NodePositionRequest) error
+	TrashList(ctx context.Context, request dto.PaginationRequest) ([]dto.NodeTrashResponse, dto.PaginationMeta, error)
+	RestoreNode(ctx context.Context, nodeId string) error
+	PurgeNode(ctx context.Context, nodeId string) error
+	TreeNode(ctx context.Context, nodeId string, request dto.NodeTreeRequest) (*dto.NodeTreeResponse, error)
+	AncestorList(ctx context.Context, nodeId string) ([]dto.NodeAncestorResponse, error)
+	LowestCommonAncestor(ctx context.Context, request dto.NodeLCARequest) (dto.NodeLCAResponse, error)
+	PathTo(ctx context.Context, nodeId string, targetId string) (dto.NodePathToResponse, error)
+	AncestorListBulk(ctx context.Context, request dto.NodeAncestorBulkRequest) ([]dto.NodePathResponse, error)
+	ChildList(ctx context.Context, nodeId string, request dto.PaginationRequest) ([]dto.NodeResponse, dto.PaginationMeta, error)
 }
@@ -3,20 +3,42 @@ package service
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"github.com/anhsbolic/closure-table-go/model/domain"
 	"github.com/anhsbolic/closure-table-go/model/dto"
 	"github.com/anhsbolic/closure-table-go/pkg"
+	"github.com/anhsbolic/closure-table-go/pkg/adjacency"
+	"github.com/anhsbolic/closure-table-go/pkg/export"
+	"github.com/anhsbolic/closure-table-go/pkg/outline"
 	"github.com/anhsbolic/closure-table-go/repository"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"io"
+	"math"
+	"slices"
 	"time"
 )
 
+const (
+	// positionGap is the distance between sibling positions after a renumber
+	positionGap int64 = 1024
+
+	// maxImportNodes is the largest number of nodes accepted by one import
+	maxImportNodes = 50000
+
+	rootListCacheKey          = "nodes:root"
+	detailCacheKeyFormat      = "nodes:detail:%s"
+	descendantsCacheKeyFormat = "nodes:descendants:%s"
+)
+
 type NodeServiceImpl struct {
 	NodeRepository        repository.NodeRepository
 	NodeClosureRepository repository.NodeClosureRepository
 	DB                    *sql.DB
+	TxOptions             *sql.TxOptions
+	TreeTxOptions         *sql.TxOptions
+	Cache                 pkg.Cache
 	Validate              *validator.Validate
 }
 
@@ -24,12 +46,16 @@ func NewNodeService(
 	nodeRepository repository.NodeRepository,
 	nodeClosureRepository repository.NodeClosureRepository,
 	db *sql.DB,
+	cache pkg.Cache,
 	validate *validator.Validate,
 ) NodeService {
 	return &NodeServiceImpl{
 		NodeRepository:        nodeRepository,
 		NodeClosureRepository: nodeClosureRepository,
 		DB:                    db,
+		TxOptions:             pkg.NewTxOptions(),
+		TreeTxOptions:         &sql.TxOptions{Isolation: sql.LevelSerializable},
+		Cache:                 cache,
 		Validate:              validate,
 	}
 }
@@ -41,86 +67,71 @@ func (service *NodeServiceImpl) Create(ctx context.Context, request dto.NodeCrea
 		return dto.NodeCreatedResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	// Check Ancestor Node
-	if request.AncestorID != nil {
-		isAncestorNodeExist, err := service.NodeRepository.CheckByID(ctx, service.DB, *request.AncestorID)
-		if err != nil {
-			return dto.NodeCreatedResponse{}, err
-		}
-		if !isAncestorNodeExist {
-			return dto.NodeCreatedResponse{}, fiber.NewError(
-				fiber.StatusUnprocessableEntity,
-				"Ancestor node is not found",
-			)
-		}
-	}
-
-	// Start transaction
-	tx, err := service.DB.Begin()
-	if err != nil {
-		return dto.NodeCreatedResponse{}, err
-	}
-
-	// Defer commit or rollback
-	defer pkg.CommitOrRollback(tx)
-
-	// Save node
+	// Prepare node
 	description := sql.NullString{Valid: false}
 	if request.Description != nil {
 		description = sql.NullString{String: *request.Description, Valid: true}
 	}
+	parentId := uuid.NullUUID{Valid: false}
+	if request.AncestorID != nil {
+		parentId = uuid.NullUUID{UUID: uuid.MustParse(*request.AncestorID), Valid: true}
+	}
+	externalId := sql.NullString{Valid: false}
+	if request.ExternalID != nil {
+		externalId = sql.NullString{String: *request.ExternalID, Valid: true}
+	}
 	node := domain.Node{
 		ID:          uuid.New(),
+		ParentID:    parentId,
+		ExternalID:  externalId,
 		Title:       request.Title,
 		Type:        request.Type,
 		Description: description,
 		CreatedAt:   sql.NullTime{Time: time.Now(), Valid: true},
 	}
-	createdNode, err := service.NodeRepository.Create(ctx, tx, node)
-	if err != nil {
-		return dto.NodeCreatedResponse{}, err
-	}
 
-	// Save NodeClosure : Self Reference
-	closure := domain.NodeClosure{
-		Ancestor:   createdNode.ID,
-		Descendant: createdNode.ID,
-		Depth:      0,
-	}
-	_, err = service.NodeClosureRepository.Save(ctx, tx, closure)
-	if err != nil {
-		return dto.NodeCreatedResponse{}, err
-	}
+	// Run in serializable transaction, retried on conflict
+	var createdNode domain.Node
+	var cacheKeys []string
+	err = pkg.WithRetryTx(ctx, service.DB, service.TreeTxOptions, func(tx *sql.Tx) error {
+		// Check External ID Is Free
+		if request.ExternalID != nil {
+			existingNode, err := service.NodeRepository.DetailByExternalID(ctx, tx, principalTenant(ctx), *request.ExternalID)
+			if err != nil {
+				return err
+			}
+			if existingNode.ID != uuid.Nil {
+				return errExternalIDTaken(*request.ExternalID)
+			}
+		}
 
-	// When Node Have Ancestor
-	if request.AncestorID != nil {
-		// Get Ancestor Closures
-		ancestorClosures, err := service.NodeClosureRepository.FindByDescendant(ctx, service.DB, *request.AncestorID)
+		var err error
+		createdNode, err = service.createNode(ctx, tx, node, request.NodePositionRequest)
 		if err != nil {
-			return dto.NodeCreatedResponse{}, err
+			return err
 		}
 
-		// Save NodeClosure : Ancestor Reference
-		depth := 1
-		for _, ancestorClosure := range ancestorClosures {
-			closure := domain.NodeClosure{
-				Ancestor:   ancestorClosure.Ancestor,
-				Descendant: createdNode.ID,
-				Depth:      depth,
-			}
-			_, err := service.NodeClosureRepository.Save(ctx, tx, closure)
-			if err != nil {
-				return dto.NodeCreatedResponse{}, err
-			}
-			depth++
-		}
+		// Collect Cached Reads Showing The Node
+		cacheKeys, err = service.nodeCacheKeys(ctx, tx, []string{createdNode.ID.String()})
+		return err
+	})
+	if err != nil {
+		return dto.NodeCreatedResponse{}, err
 	}
+	service.invalidateCache(ctx, cacheKeys)
 
 	// return response
 	return dto.ToNodeCreatedResponse(createdNode), nil
 }
 
 func (service *NodeServiceImpl) RootList(ctx context.Context) ([]dto.NodeResponse, error) {
+	// Get Cached Root Nodes
+	var response []dto.NodeResponse
+	isCached, generation := service.readCache(ctx, rootListCacheKey, &response)
+	if isCached {
+		return response, nil
+	}
+
 	// Get Root Nodes
 	rootNodes, err := service.NodeRepository.GetRootList(ctx, service.DB)
 	if err != nil {
@@ -128,10 +139,26 @@ func (service *NodeServiceImpl) RootList(ctx context.Context) ([]dto.NodeRespons
 	}
 
 	// return response
-	return dto.ToNodePaginationResponse(rootNodes), nil
+	response = dto.ToNodePaginationResponse(rootNodes)
+	service.writeCache(ctx, rootListCacheKey, generation, response)
+	return response, nil
 }
 
 func (service *NodeServiceImpl) DetailNode(ctx context.Context, nodeId string) (dto.NodeResponse, error) {
+	// Parse ID, cache keys use the canonical form mutations invalidate
+	nodeId, err := canonicalNodeID(nodeId)
+	if err != nil {
+		return dto.NodeResponse{}, fiber.ErrNotFound
+	}
+
+	// Get Cached Node
+	cacheKey := fmt.Sprintf(detailCacheKeyFormat, nodeId)
+	var response dto.NodeResponse
+	isCached, generation := service.readCache(ctx, cacheKey, &response)
+	if isCached {
+		return response, nil
+	}
+
 	// Get Node By ID
 	node, err := service.NodeRepository.DetailByID(ctx, service.DB, nodeId)
 	if err != nil {
@@ -141,10 +168,132 @@ func (service *NodeServiceImpl) DetailNode(ctx context.Context, nodeId string) (
 		return dto.NodeResponse{}, fiber.ErrNotFound
 	}
 
+	// return response
+	response = dto.ToNodeDetailResponse(node)
+	service.writeCache(ctx, cacheKey, generation, response)
+	return response, nil
+}
+
+func (service *NodeServiceImpl) DetailByExternalID(ctx context.Context, externalId string) (dto.NodeResponse, error) {
+	// Get Node By External ID
+	node, err := service.NodeRepository.DetailByExternalID(ctx, service.DB, principalTenant(ctx), externalId)
+	if err != nil {
+		return dto.NodeResponse{}, err
+	}
+	if node.ID == uuid.Nil || node.DeletedAt.Valid {
+		return dto.NodeResponse{}, fiber.ErrNotFound
+	}
+
 	// return response
 	return dto.ToNodeDetailResponse(node), nil
 }
 
+func (service *NodeServiceImpl) UpsertByExternalID(ctx context.Context, externalId string, request dto.NodeUpsertRequest) (dto.NodeResponse, bool, error) {
+	// Validate request
+	err := service.Validate.Var(externalId, "required,max=255")
+	if err != nil {
+		return dto.NodeResponse{}, false, fiber.NewError(fiber.StatusBadRequest, "external_id: "+err.Error())
+	}
+	err = service.Validate.Struct(request)
+	if err != nil {
+		return dto.NodeResponse{}, false, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Prepare node
+	description := sql.NullString{Valid: false}
+	if request.Description != nil {
+		description = sql.NullString{String: *request.Description, Valid: true}
+	}
+	parentId := uuid.NullUUID{Valid: false}
+	if request.ParentID != nil {
+		parentId = uuid.NullUUID{UUID: uuid.MustParse(*request.ParentID), Valid: true}
+	}
+	now := sql.NullTime{Time: time.Now(), Valid: true}
+
+	// Run in serializable transaction, retried on conflict
+	var node domain.Node
+	var isCreated bool
+	var cacheKeys []string
+	err = pkg.WithRetryTx(ctx, service.DB, service.TreeTxOptions, func(tx *sql.Tx) error {
+		// Get Node By External ID
+		existingNode, err := service.NodeRepository.DetailByExternalID(ctx, tx, principalTenant(ctx), externalId)
+		if err != nil {
+			return err
+		}
+		if existingNode.DeletedAt.Valid {
+			return pkg.NewAppError(
+				fiber.StatusConflict,
+				"EXTERNAL_ID_IN_TRASH",
+				"Node with this external ID is in trash, restore or purge it first",
+			)
+		}
+
+		// Create When Missing
+		if existingNode.ID == uuid.Nil {
+			isCreated = true
+			node, err = service.createNode(ctx, tx, domain.Node{
+				ID:          uuid.New(),
+				ParentID:    parentId,
+				ExternalID:  sql.NullString{String: externalId, Valid: true},
+				Title:       request.Title,
+				Type:        request.Type,
+				Description: description,
+				CreatedAt:   now,
+			}, dto.NodePositionRequest{})
+			if err != nil {
+				return err
+			}
+
+			// Collect Cached Reads Showing The Node
+			cacheKeys, err = service.nodeCacheKeys(ctx, tx, []string{node.ID.String()})
+			return err
+		}
+
+		// Collect Cached Reads Under The Current Ancestors
+		cacheKeys, err = service.nodeCacheKeys(ctx, tx, []string{existingNode.ID.String()})
+		if err != nil {
+			return err
+		}
+
+		// Update Node
+		isCreated = false
+		node = existingNode
+		node.Title = request.Title
+		node.Type = request.Type
+		node.Description = description
+		node.UpdatedAt = now
+		node, err = service.NodeRepository.Update(ctx, tx, node.ID.String(), node)
+		if err != nil {
+			return err
+		}
+
+		// Reparent When Parent Changed
+		if node.ParentID != parentId {
+			err = service.moveNode(ctx, tx, node.ID, request.ParentID, dto.NodePositionRequest{})
+			if err != nil {
+				return err
+			}
+			node.ParentID = parentId
+
+			// Collect Cached Reads Under The New Ancestors
+			newCacheKeys, err := service.nodeCacheKeys(ctx, tx, []string{node.ID.String()})
+			if err != nil {
+				return err
+			}
+			cacheKeys = append(cacheKeys, newCacheKeys...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return dto.NodeResponse{}, false, err
+	}
+	service.invalidateCache(ctx, cacheKeys)
+
+	// return response
+	return dto.ToNodeDetailResponse(node), isCreated, nil
+}
+
 func (service *NodeServiceImpl) UpdateNode(ctx context.Context, nodeId string, request dto.NodeUpdateRequest) (dto.NodeResponse, error) {
 	// Get Detail Node By ID
 	node, err := service.NodeRepository.DetailByID(ctx, service.DB, nodeId)
@@ -161,15 +310,6 @@ func (service *NodeServiceImpl) UpdateNode(ctx context.Context, nodeId string, r
 		return dto.NodeResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	// Start transaction
-	tx, err := service.DB.Begin()
-	if err != nil {
-		return dto.NodeResponse{}, err
-	}
-
-	// Defer commit or rollback
-	defer pkg.CommitOrRollback(tx)
-
 	// Update Node
 	node.Title = request.Title
 	node.Type = request.Type
@@ -177,57 +317,121 @@ func (service *NodeServiceImpl) UpdateNode(ctx context.Context, nodeId string, r
 		node.Description = sql.NullString{String: *request.Description, Valid: true}
 	}
 	node.UpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
-	updatedNode, err := service.NodeRepository.Update(ctx, tx, nodeId, node)
+	var updatedNode domain.Node
+	var cacheKeys []string
+	err = pkg.WithTx(ctx, service.DB, service.TxOptions, func(tx *sql.Tx) error {
+		var err error
+		updatedNode, err = service.NodeRepository.Update(ctx, tx, nodeId, node)
+		if err != nil {
+			return err
+		}
+
+		// Collect Cached Reads Showing The Node
+		cacheKeys, err = service.nodeCacheKeys(ctx, tx, []string{nodeId})
+		return err
+	})
 	if err != nil {
 		return dto.NodeResponse{}, err
 	}
+	service.invalidateCache(ctx, cacheKeys)
 
 	// return response
 	return dto.ToNodeDetailResponse(updatedNode), nil
 }
 
-func (service *NodeServiceImpl) DeleteNode(ctx context.Context, nodeId string) error {
-	// Check Node By ID
-	isNodeExist, err := service.NodeRepository.CheckByID(ctx, service.DB, nodeId)
+func (service *NodeServiceImpl) DeleteNode(ctx context.Context, nodeId string, request dto.NodeDeleteRequest) error {
+	// Validate request
+	err := service.Validate.Struct(request)
 	if err != nil {
-		return err
-	}
-	if !isNodeExist {
-		return fiber.ErrNotFound
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	// Start transaction
-	tx, err := service.DB.Begin()
-	if err != nil {
-		return err
-	}
+	// Run in serializable transaction, retried on conflict
+	var cacheKeys []string
+	err = pkg.WithRetryTx(ctx, service.DB, service.TreeTxOptions, func(tx *sql.Tx) error {
+		// Check Node By ID
+		isNodeExist, err := service.NodeRepository.CheckByID(ctx, tx, nodeId)
+		if err != nil {
+			return err
+		}
+		if !isNodeExist {
+			return fiber.ErrNotFound
+		}
+		deletedAt := sql.NullTime{Time: time.Now(), Valid: true}
 
-	// Defer commit or rollback
-	defer pkg.CommitOrRollback(tx)
+		// Get Descendant IDs
+		descendantIds, err := service.NodeClosureRepository.FindDescendantIdsByAncestor(ctx, tx, nodeId)
+		if err != nil {
+			return err
+		}
 
-	// Get Descendant IDs
-	descendantIds, err := service.NodeClosureRepository.FindDescendantIdsByAncestor(ctx, tx, nodeId)
-	if err != nil {
-		return err
-	}
+		// Collect Cached Reads Showing The Subtree, promoted children change parent too
+		cacheKeys, err = service.nodeCacheKeys(ctx, tx, descendantIds)
+		if err != nil {
+			return err
+		}
 
-	// Delete Node Closure : Self with All Descendants
-	err = service.NodeClosureRepository.DeleteByDescendantIds(ctx, tx, descendantIds)
-	if err != nil {
-		return err
-	}
+		// Promote Mode : reattach children to the node's parent, then trash the node alone
+		if request.Mode == dto.NodeDeleteModePromote {
+			// Get Sibling And Children Order Before Promoting
+			node, err := service.NodeRepository.DetailByID(ctx, tx, nodeId)
+			if err != nil {
+				return err
+			}
+			siblings, err := service.NodeRepository.GetSiblingPositions(ctx, tx, node.ParentID)
+			if err != nil {
+				return err
+			}
+			children, err := service.NodeRepository.GetSiblingPositions(ctx, tx, uuid.NullUUID{UUID: node.ID, Valid: true})
+			if err != nil {
+				return err
+			}
+
+			err = service.NodeRepository.PromoteChildren(ctx, tx, nodeId)
+			if err != nil {
+				return err
+			}
+
+			err = service.NodeClosureRepository.DetachAndPromoteChildren(ctx, tx, nodeId)
+			if err != nil {
+				return err
+			}
+
+			err = service.NodeRepository.SoftDeleteByDescendantIds(ctx, tx, []string{nodeId}, deletedAt)
+			if err != nil {
+				return err
+			}
+
+			// Give Promoted Children The Node's Slot
+			return service.placePromotedChildren(ctx, tx, node.ID, siblings, children)
+		}
 
-	// Delete Node with All Descendants
-	err = service.NodeRepository.DeleteByDescendantIds(ctx, tx, descendantIds)
+		// Move Node with All Descendants to Trash
+		return service.NodeRepository.SoftDeleteByDescendantIds(ctx, tx, descendantIds, deletedAt)
+	})
 	if err != nil {
 		return err
 	}
+	service.invalidateCache(ctx, cacheKeys)
 
-	// return response
 	return nil
 }
 
 func (service *NodeServiceImpl) DescendantList(ctx context.Context, nodeId string) ([]dto.NodeResponse, error) {
+	// Parse ID, cache keys use the canonical form mutations invalidate
+	nodeId, err := canonicalNodeID(nodeId)
+	if err != nil {
+		return []dto.NodeResponse{}, fiber.ErrNotFound
+	}
+
+	// Get Cached Descendant Nodes, entries are dropped when the node is trashed
+	cacheKey := fmt.Sprintf(descendantsCacheKeyFormat, nodeId)
+	var response []dto.NodeResponse
+	isCached, generation := service.readCache(ctx, cacheKey, &response)
+	if isCached {
+		return response, nil
+	}
+
 	// Check Node By ID
 	isNodeExist, err := service.NodeRepository.CheckByID(ctx, service.DB, nodeId)
 	if err != nil {
@@ -244,18 +448,18 @@ func (service *NodeServiceImpl) DescendantList(ctx context.Context, nodeId strin
 	}
 
 	// return response
-	return dto.ToNodePaginationResponse(descendantNodes), nil
+	response = dto.ToNodePaginationResponse(descendantNodes)
+	service.writeCache(ctx, cacheKey, generation, response)
+	return response, nil
 }
 
 func (service *NodeServiceImpl) MoveNode(ctx context.Context, nodeId string, request dto.NodeMoveRequest) error {
-	// Check Node By ID
-	isNodeExist, err := service.NodeRepository.CheckByID(ctx, service.DB, nodeId)
+	// Parse ID, Postgres accepts spellings uuid.Parse rejects
+	id, err := uuid.Parse(nodeId)
 	if err != nil {
-		return err
-	}
-	if !isNodeExist {
 		return fiber.ErrNotFound
 	}
+	nodeId = id.String()
 
 	// Validate request
 	err = service.Validate.Struct(request)
@@ -263,50 +467,1167 @@ func (service *NodeServiceImpl) MoveNode(ctx context.Context, nodeId string, req
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	// Check Ancestor Node
-	isAncestorNodeExist, err := service.NodeRepository.CheckByID(ctx, service.DB, request.ToAncestorID)
+	// Run in serializable transaction, retried on conflict
+	var cacheKeys []string
+	err = pkg.WithRetryTx(ctx, service.DB, service.TreeTxOptions, func(tx *sql.Tx) error {
+		// Check Node By ID
+		isNodeExist, err := service.NodeRepository.CheckByID(ctx, tx, nodeId)
+		if err != nil {
+			return err
+		}
+		if !isNodeExist {
+			return fiber.ErrNotFound
+		}
+
+		// Collect Cached Reads Under The Old Ancestors
+		cacheKeys, err = service.nodeCacheKeys(ctx, tx, []string{nodeId})
+		if err != nil {
+			return err
+		}
+
+		err = service.moveNode(ctx, tx, id, request.ToAncestorID, request.NodePositionRequest)
+		if err != nil {
+			return err
+		}
+
+		// Collect Cached Reads Under The New Ancestors
+		newCacheKeys, err := service.nodeCacheKeys(ctx, tx, []string{nodeId})
+		cacheKeys = append(cacheKeys, newCacheKeys...)
+		return err
+	})
 	if err != nil {
 		return err
 	}
-	if !isAncestorNodeExist {
-		return fiber.NewError(fiber.StatusUnprocessableEntity, "Ancestor node is not found")
+	service.invalidateCache(ctx, cacheKeys)
+
+	return nil
+}
+
+func (service *NodeServiceImpl) ChildList(ctx context.Context, nodeId string, request dto.PaginationRequest) ([]dto.NodeResponse, dto.PaginationMeta, error) {
+	// Validate request
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return []dto.NodeResponse{}, dto.PaginationMeta{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if request.Limit == 0 {
+		request.Limit = dto.DefaultPaginationLimit
 	}
 
-	// Start transaction
-	tx, err := service.DB.Begin()
+	// Check Node By ID
+	isNodeExist, err := service.NodeRepository.CheckByID(ctx, service.DB, nodeId)
 	if err != nil {
-		return err
+		return []dto.NodeResponse{}, dto.PaginationMeta{}, err
+	}
+	if !isNodeExist {
+		return []dto.NodeResponse{}, dto.PaginationMeta{}, fiber.ErrNotFound
 	}
 
-	// Defer commit or rollback
-	defer pkg.CommitOrRollback(tx)
+	// Count Children
+	total, err := service.NodeRepository.CountChildren(ctx, service.DB, nodeId)
+	if err != nil {
+		return []dto.NodeResponse{}, dto.PaginationMeta{}, err
+	}
 
-	// Get New Path For Node
-	newClosures, err := service.NodeClosureRepository.GetNewClosures(ctx, tx, nodeId, request.ToAncestorID)
+	// Get Children
+	childNodes, err := service.NodeRepository.GetChildList(ctx, service.DB, nodeId, request.Limit, request.Offset)
 	if err != nil {
-		return err
+		return []dto.NodeResponse{}, dto.PaginationMeta{}, err
+	}
+
+	// return response
+	meta := dto.PaginationMeta{
+		Limit:  request.Limit,
+		Offset: request.Offset,
+		Total:  total,
 	}
+	return dto.ToNodePaginationResponse(childNodes), meta, nil
+}
 
-	// Get Descendant IDs
-	descendantIds, err := service.NodeClosureRepository.FindDescendantIdsByAncestor(ctx, tx, nodeId)
+func (service *NodeServiceImpl) AncestorList(ctx context.Context, nodeId string) ([]dto.NodeAncestorResponse, error) {
+	// Check Node By ID
+	isNodeExist, err := service.NodeRepository.CheckByID(ctx, service.DB, nodeId)
 	if err != nil {
-		return err
+		return []dto.NodeAncestorResponse{}, err
+	}
+	if !isNodeExist {
+		return []dto.NodeAncestorResponse{}, fiber.ErrNotFound
 	}
 
-	// Delete Node Closure : Self with All Descendants
-	err = service.NodeClosureRepository.DeleteByDescendantIds(ctx, tx, descendantIds)
+	// Get Ancestor Path
+	ancestors, err := service.NodeRepository.GetAncestorListByDescendantIds(ctx, service.DB, []string{nodeId})
 	if err != nil {
-		return err
+		return []dto.NodeAncestorResponse{}, err
+	}
+
+	// return response
+	return dto.ToNodeAncestorResponse(ancestors), nil
+}
+
+func (service *NodeServiceImpl) AncestorListBulk(ctx context.Context, request dto.NodeAncestorBulkRequest) ([]dto.NodePathResponse, error) {
+	// Validate request
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return []dto.NodePathResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	// Save New Node Closure For Self and All Descendants Under New Ancestor
-	for _, closure := range newClosures {
-		_, err := service.NodeClosureRepository.Save(ctx, tx, closure)
+	// Get Ancestor Paths
+	ancestors, err := service.NodeRepository.GetAncestorListByDescendantIds(ctx, service.DB, request.NodeIDs)
+	if err != nil {
+		return []dto.NodePathResponse{}, err
+	}
+
+	// Group Ancestors By Node
+	ancestorsByNode := map[uuid.UUID][]domain.NodeAncestor{}
+	for _, ancestor := range ancestors {
+		ancestorsByNode[ancestor.Descendant] = append(ancestorsByNode[ancestor.Descendant], ancestor)
+	}
+
+	// return response in request order, unknown nodes get an empty path
+	pathResponses := []dto.NodePathResponse{}
+	for _, nodeId := range request.NodeIDs {
+		id := uuid.MustParse(nodeId)
+		pathResponses = append(pathResponses, dto.NodePathResponse{
+			NodeID: id,
+			Path:   dto.ToNodeAncestorResponse(ancestorsByNode[id]),
+		})
+	}
+	return pathResponses, nil
+}
+
+func (service *NodeServiceImpl) LowestCommonAncestor(ctx context.Context, request dto.NodeLCARequest) (dto.NodeLCAResponse, error) {
+	// Validate request
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return dto.NodeLCAResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	nodeIds := make([]string, 0, len(request.IDs))
+	for _, id := range request.IDs {
+		nodeId, err := canonicalNodeID(id)
 		if err != nil {
-			return err
+			return dto.NodeLCAResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
 		}
+		nodeIds = append(nodeIds, nodeId)
 	}
+	slices.Sort(nodeIds)
+	nodeIds = slices.Compact(nodeIds)
 
-	// return success
-	return nil
+	// Check Nodes By IDs
+	total, err := service.NodeRepository.CountByIDs(ctx, service.DB, nodeIds)
+	if err != nil {
+		return dto.NodeLCAResponse{}, err
+	}
+	if total != len(nodeIds) {
+		return dto.NodeLCAResponse{}, fiber.ErrNotFound
+	}
+
+	// Get Deepest Shared Ancestor
+	ancestor, err := service.NodeRepository.FindLowestCommonAncestor(ctx, service.DB, nodeIds)
+	if err != nil {
+		return dto.NodeLCAResponse{}, err
+	}
+	if ancestor.Node.ID == uuid.Nil {
+		return dto.NodeLCAResponse{}, errNodesInDifferentTrees()
+	}
+
+	// return response
+	return dto.ToNodeLCAResponse(ancestor), nil
+}
+
+func (service *NodeServiceImpl) PathTo(ctx context.Context, nodeId string, targetId string) (dto.NodePathToResponse, error) {
+	// Parse IDs, paths are matched on the canonical form
+	fromId, err := uuid.Parse(nodeId)
+	if err != nil {
+		return dto.NodePathToResponse{}, fiber.ErrNotFound
+	}
+	toId, err := uuid.Parse(targetId)
+	if err != nil {
+		return dto.NodePathToResponse{}, fiber.ErrNotFound
+	}
+
+	// Check Nodes By IDs
+	for _, id := range []uuid.UUID{fromId, toId} {
+		isNodeExist, err := service.NodeRepository.CheckByID(ctx, service.DB, id.String())
+		if err != nil {
+			return dto.NodePathToResponse{}, err
+		}
+		if !isNodeExist {
+			return dto.NodePathToResponse{}, fiber.ErrNotFound
+		}
+	}
+
+	// Get Both Paths From Root
+	ancestors, err := service.NodeRepository.GetAncestorListByDescendantIds(ctx, service.DB, []string{fromId.String(), toId.String()})
+	if err != nil {
+		return dto.NodePathToResponse{}, err
+	}
+	var fromPath, toPath []domain.NodeAncestor
+	for _, ancestor := range ancestors {
+		if ancestor.Descendant == fromId {
+			fromPath = append(fromPath, ancestor)
+		}
+		if ancestor.Descendant == toId {
+			toPath = append(toPath, ancestor)
+		}
+	}
+
+	// Paths share the root down to the lowest common ancestor
+	shared := 0
+	for shared < len(fromPath) && shared < len(toPath) && fromPath[shared].Node.ID == toPath[shared].Node.ID {
+		shared++
+	}
+	if shared == 0 {
+		return dto.NodePathToResponse{}, errNodesInDifferentTrees()
+	}
+
+	// Walk up from the node to the common ancestor, then down to the target
+	path := slices.Clone(fromPath[shared-1:])
+	slices.Reverse(path)
+	path = append(path, toPath[shared:]...)
+
+	// return response
+	return dto.NodePathToResponse{
+		Distance: len(path) - 1,
+		Path:     dto.ToNodeAncestorResponse(path),
+	}, nil
+}
+
+func (service *NodeServiceImpl) TreeNode(ctx context.Context, nodeId string, request dto.NodeTreeRequest) (*dto.NodeTreeResponse, error) {
+	// Validate request
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	maxDepth := request.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = math.MaxInt32
+	}
+
+	// Check Node By ID
+	isNodeExist, err := service.NodeRepository.CheckByID(ctx, service.DB, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	if !isNodeExist {
+		return nil, fiber.ErrNotFound
+	}
+
+	// Get Subtree
+	descendants, err := service.NodeRepository.GetSubtree(ctx, service.DB, nodeId, maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	// return response
+	return dto.ToNodeTreeResponse(descendants), nil
+}
+
+func (service *NodeServiceImpl) TrashList(ctx context.Context, request dto.PaginationRequest) ([]dto.NodeTrashResponse, dto.PaginationMeta, error) {
+	// Validate request
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return []dto.NodeTrashResponse{}, dto.PaginationMeta{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if request.Limit == 0 {
+		request.Limit = dto.DefaultPaginationLimit
+	}
+
+	// Count Trashed Nodes
+	total, err := service.NodeRepository.CountTrash(ctx, service.DB)
+	if err != nil {
+		return []dto.NodeTrashResponse{}, dto.PaginationMeta{}, err
+	}
+
+	// Get Trashed Nodes
+	trashedNodes, err := service.NodeRepository.GetTrashList(ctx, service.DB, request.Limit, request.Offset)
+	if err != nil {
+		return []dto.NodeTrashResponse{}, dto.PaginationMeta{}, err
+	}
+
+	// return response
+	meta := dto.PaginationMeta{
+		Limit:  request.Limit,
+		Offset: request.Offset,
+		Total:  total,
+	}
+	return dto.ToNodeTrashResponse(trashedNodes), meta, nil
+}
+
+func (service *NodeServiceImpl) RestoreNode(ctx context.Context, nodeId string) error {
+	// Run in serializable transaction, retried on conflict
+	var cacheKeys []string
+	err := pkg.WithRetryTx(ctx, service.DB, service.TreeTxOptions, func(tx *sql.Tx) error {
+		// Get Trashed Node By ID
+		node, err := service.NodeRepository.DetailDeletedByID(ctx, tx, nodeId)
+		if err != nil {
+			return err
+		}
+		if node.ID == uuid.Nil {
+			return fiber.ErrNotFound
+		}
+
+		// Reject restore while an ancestor is still in trash
+		isAncestorDeleted, err := service.NodeRepository.CheckDeletedAncestorByID(ctx, tx, nodeId)
+		if err != nil {
+			return err
+		}
+		if isAncestorDeleted {
+			return pkg.NewAppError(
+				fiber.StatusUnprocessableEntity,
+				"ANCESTOR_IN_TRASH",
+				"Node cannot be restored while one of its ancestors is in trash",
+			)
+		}
+
+		// Restore Node with Descendants Trashed Together
+		err = service.NodeRepository.RestoreByAncestor(ctx, tx, nodeId, node.DeletedAt)
+		if err != nil {
+			return err
+		}
+
+		// Collect Cached Reads Showing The Restored Node
+		cacheKeys, err = service.nodeCacheKeys(ctx, tx, []string{nodeId})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	service.invalidateCache(ctx, cacheKeys)
+
+	return nil
+}
+
+func (service *NodeServiceImpl) PurgeNode(ctx context.Context, nodeId string) error {
+	// Run in serializable transaction, retried on conflict
+	return pkg.WithRetryTx(ctx, service.DB, service.TreeTxOptions, func(tx *sql.Tx) error {
+		// Get Trashed Node By ID
+		node, err := service.NodeRepository.DetailDeletedByID(ctx, tx, nodeId)
+		if err != nil {
+			return err
+		}
+		if node.ID == uuid.Nil {
+			return fiber.ErrNotFound
+		}
+
+		// Get Descendant IDs
+		descendantIds, err := service.NodeClosureRepository.FindDescendantIdsByAncestor(ctx, tx, nodeId)
+		if err != nil {
+			return err
+		}
+
+		// Delete Node with All Descendants, their closures are removed by cascade
+		return service.NodeRepository.DeleteByDescendantIds(ctx, tx, descendantIds)
+	})
+}
+
+func (service *NodeServiceImpl) ImportNodes(ctx context.Context, request dto.NodeImportRequest) (dto.NodeImportedResponse, error) {
+	// Validate request
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return dto.NodeImportedResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Flatten Nested Nodes, Parents Before Children
+	createdAt := sql.NullTime{Time: time.Now(), Valid: true}
+	nodes := flattenImportItems(request.Nodes, uuid.NullUUID{}, createdAt, nil)
+	if len(nodes) > maxImportNodes {
+		return dto.NodeImportedResponse{}, fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Sprintf("Import is limited to %d nodes", maxImportNodes),
+		)
+	}
+
+	// Run in serializable transaction, retried on conflict
+	var cacheKeys []string
+	err = pkg.WithRetryTx(ctx, service.DB, service.TreeTxOptions, func(tx *sql.Tx) error {
+		var err error
+		cacheKeys, err = service.importNodes(ctx, tx, request.ParentID, nodes)
+		return err
+	})
+	if err != nil {
+		return dto.NodeImportedResponse{}, err
+	}
+	service.invalidateCache(ctx, cacheKeys)
+
+	// return response
+	return dto.NodeImportedResponse{
+		IDs:   importRootIds(nodes),
+		Count: len(nodes),
+	}, nil
+}
+
+func (service *NodeServiceImpl) ImportOutline(ctx context.Context, request dto.NodeOutlineImportRequest, body io.Reader) (dto.NodeImportedResponse, error) {
+	// Validate request
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return dto.NodeImportedResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Parse Outline
+	var nodes []outline.Node
+	switch request.Format {
+	case dto.NodeOutlineFormatOPML:
+		nodes, err = outline.ParseOPML(body)
+	case dto.NodeOutlineFormatMarkdown:
+		nodes, err = outline.ParseMarkdown(body)
+	}
+	if err != nil {
+		return dto.NodeImportedResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Import as nested nodes
+	return service.ImportNodes(ctx, dto.NodeImportRequest{
+		ParentID: request.ParentID,
+		Nodes:    outlineImportItems(nodes),
+	})
+}
+
+func (service *NodeServiceImpl) ImportCSV(ctx context.Context, request dto.NodeCSVImportRequest, body io.Reader) (dto.NodeCSVImportedResponse, error) {
+	// Validate request
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return dto.NodeCSVImportedResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Read Rows
+	rows, err := adjacency.ReadCSV(body)
+	if err != nil {
+		return dto.NodeCSVImportedResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if len(rows) > maxImportNodes {
+		return dto.NodeCSVImportedResponse{}, fiber.NewError(
+			fiber.StatusBadRequest,
+			fmt.Sprintf("Import is limited to %d nodes", maxImportNodes),
+		)
+	}
+
+	// Order Rows Parents First, nothing is imported when any row is invalid
+	rows, rowErrors := adjacency.Sort(rows)
+	if rowErrors != nil {
+		return dto.NodeCSVImportedResponse{}, pkg.NewAppError(
+			fiber.StatusUnprocessableEntity,
+			"INVALID_CSV_ROWS",
+			rowErrors.Error(),
+		).WithDetails(rowErrors)
+	}
+
+	// Map External IDs to New Nodes
+	createdAt := sql.NullTime{Time: time.Now(), Valid: true}
+	idMapping := make(map[string]uuid.UUID, len(rows))
+	externalIds := make([]string, 0, len(rows))
+	lines := make(map[string]int, len(rows))
+	siblingCounts := map[string]int64{}
+	nodes := make([]domain.Node, 0, len(rows))
+	for _, row := range rows {
+		parentId := uuid.NullUUID{Valid: false}
+		if row.ParentExternalID != "" {
+			parentId = uuid.NullUUID{UUID: idMapping[row.ParentExternalID], Valid: true}
+		}
+		siblingCounts[row.ParentExternalID]++
+		node := domain.Node{
+			ID:          uuid.New(),
+			ParentID:    parentId,
+			Title:       row.Title,
+			Type:        row.Type,
+			Description: sql.NullString{Valid: false},
+			Position:    siblingCounts[row.ParentExternalID] * positionGap,
+			CreatedAt:   createdAt,
+			ExternalID:  sql.NullString{String: row.ExternalID, Valid: true},
+		}
+		idMapping[row.ExternalID] = node.ID
+		externalIds = append(externalIds, row.ExternalID)
+		lines[row.ExternalID] = row.Line
+		nodes = append(nodes, node)
+	}
+
+	// Run in serializable transaction, retried on conflict
+	var cacheKeys []string
+	err = pkg.WithRetryTx(ctx, service.DB, service.TreeTxOptions, func(tx *sql.Tx) error {
+		// Check External IDs Are Free
+		existingIds, err := service.NodeRepository.FindExistingExternalIDs(ctx, tx, principalTenant(ctx), externalIds)
+		if err != nil {
+			return err
+		}
+		if len(existingIds) > 0 {
+			var rowErrors adjacency.Errors
+			for _, existingId := range existingIds {
+				rowErrors = append(rowErrors, adjacency.RowError{
+					Line:       lines[existingId],
+					ExternalID: existingId,
+					Message:    "external_id already belongs to another node",
+				})
+			}
+			slices.SortFunc(rowErrors, func(a, b adjacency.RowError) int {
+				return a.Line - b.Line
+			})
+			return pkg.NewAppError(
+				fiber.StatusConflict,
+				"EXTERNAL_ID_TAKEN",
+				rowErrors.Error(),
+			).WithDetails(rowErrors)
+		}
+
+		cacheKeys, err = service.importNodes(ctx, tx, request.ParentID, nodes)
+		return err
+	})
+	if err != nil {
+		return dto.NodeCSVImportedResponse{}, err
+	}
+	service.invalidateCache(ctx, cacheKeys)
+
+	// return response
+	return dto.NodeCSVImportedResponse{
+		IDs:       importRootIds(nodes),
+		Count:     len(nodes),
+		IDMapping: idMapping,
+	}, nil
+}
+
+func (service *NodeServiceImpl) ExportNode(ctx context.Context, nodeId string, request dto.NodeExportRequest) (func(w io.Writer) error, error) {
+	// Validate request
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Check Node By ID
+	isNodeExist, err := service.NodeRepository.CheckByID(ctx, service.DB, nodeId)
+	if err != nil {
+		return nil, err
+	}
+	if !isNodeExist {
+		return nil, fiber.ErrNotFound
+	}
+
+	// return writer streaming the subtree row by row
+	return func(w io.Writer) error {
+		var encoder export.Encoder
+		switch request.Format {
+		case dto.NodeExportFormatNDJSON:
+			encoder = export.NewNDJSONEncoder(w)
+		case dto.NodeExportFormatCSV:
+			encoder = export.NewCSVEncoder(w)
+		case dto.NodeExportFormatOPML:
+			encoder = outline.NewOPMLEncoder(w)
+		case dto.NodeExportFormatMarkdown:
+			encoder = outline.NewMarkdownEncoder(w)
+		default:
+			encoder = export.NewJSONEncoder(w)
+		}
+
+		err := service.NodeRepository.StreamSubtree(ctx, service.DB, nodeId, encoder.Encode)
+		if err != nil {
+			return err
+		}
+		return encoder.Close()
+	}, nil
+}
+
+func (service *NodeServiceImpl) CopyNode(ctx context.Context, nodeId string, request dto.NodeCopyRequest) (dto.NodeCopiedResponse, error) {
+	// Parse ID, the mapping is keyed on parsed IDs and Postgres accepts spellings uuid.Parse rejects
+	id, err := uuid.Parse(nodeId)
+	if err != nil {
+		return dto.NodeCopiedResponse{}, fiber.ErrNotFound
+	}
+	nodeId = id.String()
+
+	// Validate request
+	err = service.Validate.Struct(request)
+	if err != nil {
+		return dto.NodeCopiedResponse{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	// Run in serializable transaction, retried on conflict
+	var response dto.NodeCopiedResponse
+	var cacheKeys []string
+	err = pkg.WithRetryTx(ctx, service.DB, service.TreeTxOptions, func(tx *sql.Tx) error {
+		// Check Node By ID
+		isNodeExist, err := service.NodeRepository.CheckByID(ctx, tx, nodeId)
+		if err != nil {
+			return err
+		}
+		if !isNodeExist {
+			return fiber.ErrNotFound
+		}
+
+		// Check Ancestor Node, a nil ancestor copies to root
+		parentId := uuid.NullUUID{Valid: false}
+		if request.ToAncestorID != nil {
+			isAncestorNodeExist, err := service.NodeRepository.CheckByID(ctx, tx, *request.ToAncestorID)
+			if err != nil {
+				return err
+			}
+			if !isAncestorNodeExist {
+				return fiber.NewError(fiber.StatusUnprocessableEntity, "Ancestor node is not found")
+			}
+			parentId = uuid.NullUUID{UUID: uuid.MustParse(*request.ToAncestorID), Valid: true}
+		}
+
+		// Map Node with All Descendants to New IDs
+		oldIds, err := service.NodeRepository.FindActiveDescendantIds(ctx, tx, nodeId)
+		if err != nil {
+			return err
+		}
+		newIds := make([]string, 0, len(oldIds))
+		idMapping := map[uuid.UUID]uuid.UUID{}
+		for _, oldId := range oldIds {
+			newId := uuid.New()
+			newIds = append(newIds, newId.String())
+			idMapping[uuid.MustParse(oldId)] = newId
+		}
+		newRootId := idMapping[id]
+
+		// Resolve Position Of The Copy Among Its Siblings
+		position, err := service.resolvePosition(ctx, tx, newRootId, parentId, request.NodePositionRequest)
+		if err != nil {
+			return err
+		}
+
+		// Copy Nodes, copies belong to the caller's tenant like created and imported nodes
+		createdAt := sql.NullTime{Time: time.Now(), Valid: true}
+		err = service.NodeRepository.CreateCopies(ctx, tx, nodeId, oldIds, newIds, parentId, position, createdAt, principalTenant(ctx))
+		if err != nil {
+			return err
+		}
+
+		// Copy Node Closures Inside The Subtree
+		err = service.NodeClosureRepository.SaveCopies(ctx, tx, oldIds, newIds)
+		if err != nil {
+			return err
+		}
+
+		// Attach Copy To New Ancestors
+		if request.ToAncestorID != nil {
+			err = service.NodeClosureRepository.SaveAncestorLinks(ctx, tx, newRootId.String(), *request.ToAncestorID)
+			if err != nil {
+				return err
+			}
+		}
+
+		response = dto.NodeCopiedResponse{
+			ID:        newRootId,
+			IDMapping: idMapping,
+		}
+
+		// Collect Cached Reads Showing The Copy
+		cacheKeys, err = service.nodeCacheKeys(ctx, tx, []string{newRootId.String()})
+		return err
+	})
+	if err != nil {
+		return dto.NodeCopiedResponse{}, err
+	}
+	service.invalidateCache(ctx, cacheKeys)
+
+	// return response
+	return response, nil
+}
+
+func (service *NodeServiceImpl) ReorderNode(ctx context.Context, nodeId string, request dto.NodePositionRequest) error {
+	// Validate request
+	err := service.Validate.Struct(request)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if request.BeforeID == nil && request.AfterID == nil && request.Position == nil {
+		return fiber.NewError(fiber.StatusBadRequest, "One of before_id, after_id or position is required")
+	}
+
+	// Run in serializable transaction, retried on conflict
+	var isRoot bool
+	err = pkg.WithRetryTx(ctx, service.DB, service.TreeTxOptions, func(tx *sql.Tx) error {
+		// Get Node By ID
+		node, err := service.NodeRepository.DetailByID(ctx, tx, nodeId)
+		if err != nil {
+			return err
+		}
+		if node.ID == uuid.Nil {
+			return fiber.ErrNotFound
+		}
+		isRoot = !node.ParentID.Valid
+
+		// Update Position Among Siblings
+		position, err := service.resolvePosition(ctx, tx, node.ID, node.ParentID, request)
+		if err != nil {
+			return err
+		}
+		return service.NodeRepository.UpdatePosition(ctx, tx, nodeId, position)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Only the root list is cached in sibling order
+	if isRoot {
+		service.invalidateCache(ctx, []string{rootListCacheKey})
+	}
+
+	return nil
+}
+
+// resolvePosition returns the position placing the node before_id, after_id or at the index
+// requested among the other children of parentId, appending when nothing is requested
+func (service *NodeServiceImpl) resolvePosition(ctx context.Context, tx *sql.Tx, nodeId uuid.UUID, parentId uuid.NullUUID, request dto.NodePositionRequest) (int64, error) {
+	for renumbered := false; ; renumbered = true {
+		// Get Siblings Without The Node Itself
+		siblings, err := service.NodeRepository.GetSiblingPositions(ctx, tx, parentId)
+		if err != nil {
+			return 0, err
+		}
+		siblings = slices.DeleteFunc(siblings, func(sibling domain.Node) bool {
+			return sibling.ID == nodeId
+		})
+
+		// Find Index To Insert At
+		index := len(siblings)
+		if request.BeforeID != nil || request.AfterID != nil {
+			siblingId := request.BeforeID
+			if siblingId == nil {
+				siblingId = request.AfterID
+			}
+			index = slices.IndexFunc(siblings, func(sibling domain.Node) bool {
+				return sibling.ID.String() == *siblingId
+			})
+			if index < 0 {
+				return 0, pkg.NewAppError(
+					fiber.StatusUnprocessableEntity,
+					"INVALID_SIBLING",
+					"Sibling node is not found under the target parent",
+				)
+			}
+			if request.AfterID != nil {
+				index++
+			}
+		} else if request.Position != nil {
+			index = min(*request.Position, len(siblings))
+		}
+
+		// Take The Middle Of The Gap
+		switch {
+		case len(siblings) == 0:
+			return positionGap, nil
+		case index == 0:
+			return siblings[0].Position - positionGap, nil
+		case index == len(siblings):
+			return siblings[len(siblings)-1].Position + positionGap, nil
+		}
+		previous, next := siblings[index-1].Position, siblings[index].Position
+		if next-previous > 1 {
+			return previous + (next-previous)/2, nil
+		}
+		if renumbered {
+			return 0, fmt.Errorf("no position left between siblings %s and %s", siblings[index-1].ID, siblings[index].ID)
+		}
+
+		// Gap Is Used Up, Spread Siblings And Try Again
+		err = service.NodeRepository.RenumberSiblings(ctx, tx, parentId, positionGap)
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// placePromotedChildren gives children the slot their deleted parent had among siblings, keeping
+// their order, and spreads every sibling again when the gap between the neighbours is too small
+func (service *NodeServiceImpl) placePromotedChildren(ctx context.Context, tx *sql.Tx, nodeId uuid.UUID, siblings []domain.Node, children []domain.Node) error {
+	if len(children) == 0 {
+		return nil
+	}
+
+	// Find Neighbours Of The Deleted Node
+	index := slices.IndexFunc(siblings, func(sibling domain.Node) bool {
+		return sibling.ID == nodeId
+	})
+	before, after := siblings, []domain.Node(nil)
+	if index >= 0 {
+		before, after = siblings[:index], siblings[index+1:]
+	}
+	count := int64(len(children))
+	var previous, next int64
+	switch {
+	case len(before) == 0 && len(after) == 0:
+		previous, next = 0, (count+1)*positionGap
+	case len(before) == 0:
+		next = after[0].Position
+		previous = next - (count+1)*positionGap
+	case len(after) == 0:
+		previous = before[len(before)-1].Position
+		next = previous + (count+1)*positionGap
+	default:
+		previous, next = before[len(before)-1].Position, after[0].Position
+	}
+
+	// Spread Children Over The Gap
+	ids := make([]string, 0, len(siblings)+len(children))
+	positions := make([]int64, 0, len(siblings)+len(children))
+	step := (next - previous) / (count + 1)
+	if step >= 1 {
+		for i, child := range children {
+			ids = append(ids, child.ID.String())
+			positions = append(positions, previous+step*int64(i+1))
+		}
+		return service.NodeRepository.UpdatePositions(ctx, tx, ids, positions)
+	}
+
+	// Gap Is Used Up, Renumber All Siblings In Their New Order
+	for i, sibling := range slices.Concat(before, children, after) {
+		ids = append(ids, sibling.ID.String())
+		positions = append(positions, int64(i+1)*positionGap)
+	}
+	return service.NodeRepository.UpdatePositions(ctx, tx, ids, positions)
+}
+
+// nodeCacheKeys lists the cached reads showing the given nodes: their details, the descendant lists
+// of the nodes and of all their ancestors, and the root list
+func (service *NodeServiceImpl) nodeCacheKeys(ctx context.Context, db pkg.DBTX, nodeIds []string) ([]string, error) {
+	ancestorIds, err := service.NodeClosureRepository.FindAncestorIdsByDescendants(ctx, db, nodeIds)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, 1+len(nodeIds)+len(ancestorIds))
+	keys = append(keys, rootListCacheKey)
+	for _, nodeId := range nodeIds {
+		if canonicalId, err := canonicalNodeID(nodeId); err == nil {
+			nodeId = canonicalId
+		}
+		keys = append(keys, fmt.Sprintf(detailCacheKeyFormat, nodeId))
+	}
+	for _, ancestorId := range ancestorIds {
+		keys = append(keys, fmt.Sprintf(descendantsCacheKeyFormat, ancestorId))
+	}
+
+	return keys, nil
+}
+
+// invalidateCache drops cached reads once a mutation is committed, failures are only logged since
+// the mutation is already saved and entries expire on their own
+func (service *NodeServiceImpl) invalidateCache(ctx context.Context, keys []string) {
+	err := service.Cache.Delete(ctx, keys...)
+	if err != nil {
+		pkg.NewLogger().Error(err)
+	}
+}
+
+// readCache fills value from the cache, a failing cache counts as a miss. On a miss it returns the
+// generation to pass to writeCache
+func (service *NodeServiceImpl) readCache(ctx context.Context, key string, value interface{}) (bool, string) {
+	isCached, generation, err := service.Cache.Get(ctx, key, value)
+	if err != nil {
+		pkg.NewLogger().Error(err)
+		return false, generation
+	}
+
+	return isCached, generation
+}
+
+// writeCache stores value in the cache unless the key was invalidated since readCache returned
+// generation, failures are only logged
+func (service *NodeServiceImpl) writeCache(ctx context.Context, key string, generation string, value interface{}) {
+	err := service.Cache.Set(ctx, key, generation, value)
+	if err != nil {
+		pkg.NewLogger().Error(err)
+	}
+}
+
+// errNodesInDifferentTrees reports nodes that share no ancestor
+func errNodesInDifferentTrees() error {
+	return pkg.NewAppError(
+		fiber.StatusUnprocessableEntity,
+		"NODES_IN_DIFFERENT_TREES",
+		"Nodes do not belong to the same tree",
+	)
+}
+
+// canonicalNodeID returns the lowercase hyphenated form of a node ID, Postgres also accepts
+// uppercase and braced IDs so comparisons and cache keys must not use the raw value
+func canonicalNodeID(nodeId string) (string, error) {
+	id, err := uuid.Parse(nodeId)
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// principalTenant returns the tenant of the authenticated caller, empty for callers without one
+func principalTenant(ctx context.Context) string {
+	principal, _ := pkg.PrincipalFromContext(ctx)
+	return principal.Tenant
+}
+
+// errExternalIDTaken reports an external ID that already belongs to another node
+func errExternalIDTaken(externalId string) error {
+	return pkg.NewAppError(
+		fiber.StatusConflict,
+		"EXTERNAL_ID_TAKEN",
+		fmt.Sprintf("External ID %q already belongs to another node", externalId),
+	)
+}
+
+// createNode saves a new node below its parent, or as a root, with its closure rows
+func (service *NodeServiceImpl) createNode(ctx context.Context, tx *sql.Tx, node domain.Node, positionRequest dto.NodePositionRequest) (domain.Node, error) {
+	// Node Belongs To The Caller's Tenant, which scopes its external ID
+	node.Tenant = principalTenant(ctx)
+
+	// Check Ancestor Node
+	ancestorId := node.ParentID.UUID.String()
+	if node.ParentID.Valid {
+		isAncestorNodeExist, err := service.NodeRepository.CheckByID(ctx, tx, ancestorId)
+		if err != nil {
+			return domain.Node{}, err
+		}
+		if !isAncestorNodeExist {
+			return domain.Node{}, fiber.NewError(fiber.StatusUnprocessableEntity, "Ancestor node is not found")
+		}
+	}
+
+	// Resolve Position Among Siblings
+	var err error
+	node.Position, err = service.resolvePosition(ctx, tx, node.ID, node.ParentID, positionRequest)
+	if err != nil {
+		return domain.Node{}, err
+	}
+
+	// Save node
+	createdNode, err := service.NodeRepository.Create(ctx, tx, node)
+	if err != nil {
+		return domain.Node{}, err
+	}
+
+	// Save NodeClosure : Self Reference
+	closure := domain.NodeClosure{
+		Ancestor:   createdNode.ID,
+		Descendant: createdNode.ID,
+		Depth:      0,
+	}
+	_, err = service.NodeClosureRepository.Save(ctx, tx, closure)
+	if err != nil {
+		return domain.Node{}, err
+	}
+
+	// When Node Have Ancestor
+	if node.ParentID.Valid {
+		// Get Ancestor Closures
+		ancestorClosures, err := service.NodeClosureRepository.FindByDescendant(ctx, tx, ancestorId)
+		if err != nil {
+			return domain.Node{}, err
+		}
+
+		// Save NodeClosure : Ancestor Reference
+		depth := 1
+		for _, ancestorClosure := range ancestorClosures {
+			closure := domain.NodeClosure{
+				Ancestor:   ancestorClosure.Ancestor,
+				Descendant: createdNode.ID,
+				Depth:      depth,
+			}
+			_, err := service.NodeClosureRepository.Save(ctx, tx, closure)
+			if err != nil {
+				return domain.Node{}, err
+			}
+			depth++
+		}
+	}
+
+	return createdNode, nil
+}
+
+// moveNode reparents an existing node with its subtree, a nil ancestor promotes it to root
+func (service *NodeServiceImpl) moveNode(ctx context.Context, tx *sql.Tx, id uuid.UUID, toAncestorId *string, positionRequest dto.NodePositionRequest) error {
+	nodeId := id.String()
+
+	// Check Ancestor Node, a nil ancestor promotes the node to root
+	if toAncestorId != nil {
+		isAncestorNodeExist, err := service.NodeRepository.CheckByID(ctx, tx, *toAncestorId)
+		if err != nil {
+			return err
+		}
+		if !isAncestorNodeExist {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, "Ancestor node is not found")
+		}
+
+		// Reject move into the node itself or one of its descendants
+		isInsideSubtree := *toAncestorId == nodeId
+		if !isInsideSubtree {
+			isInsideSubtree, err = service.NodeClosureRepository.CheckByAncestorAndDescendant(ctx, tx, nodeId, *toAncestorId)
+			if err != nil {
+				return err
+			}
+		}
+		if isInsideSubtree {
+			return pkg.NewAppError(
+				fiber.StatusUnprocessableEntity,
+				"MOVE_INTO_OWN_SUBTREE",
+				"Node cannot be moved into itself or one of its descendants",
+			)
+		}
+	}
+
+	// Update Parent
+	parentId := uuid.NullUUID{Valid: false}
+	if toAncestorId != nil {
+		parentId = uuid.NullUUID{UUID: uuid.MustParse(*toAncestorId), Valid: true}
+	}
+	err := service.NodeRepository.UpdateParentID(ctx, tx, nodeId, parentId)
+	if err != nil {
+		return err
+	}
+
+	// Update Position Among New Siblings
+	position, err := service.resolvePosition(ctx, tx, id, parentId, positionRequest)
+	if err != nil {
+		return err
+	}
+	err = service.NodeRepository.UpdatePosition(ctx, tx, nodeId, position)
+	if err != nil {
+		return err
+	}
+
+	// Detach Subtree From Old Ancestors
+	err = service.NodeClosureRepository.DeleteAncestorLinks(ctx, tx, nodeId)
+	if err != nil {
+		return err
+	}
+
+	// Attach Subtree To New Ancestors
+	if toAncestorId != nil {
+		return service.NodeClosureRepository.SaveAncestorLinks(ctx, tx, nodeId, *toAncestorId)
+	}
+
+	return nil
+}
+
+// importNodes saves nodes ordered parents first, nodes without a parent are appended under parentId,
+// and builds every closure row in memory so both tables are written with bulk inserts. It returns the
+// cache keys to drop once the import is committed
+func (service *NodeServiceImpl) importNodes(ctx context.Context, tx *sql.Tx, parentId *string, nodes []domain.Node) ([]string, error) {
+	// Nodes Belong To The Caller's Tenant, which scopes their external IDs
+	tenant := principalTenant(ctx)
+	for i := range nodes {
+		nodes[i].Tenant = tenant
+	}
+
+	// Check Parent Node and Get Its Closures
+	rootParentId := uuid.NullUUID{Valid: false}
+	var parentClosures []domain.NodeClosure
+	if parentId != nil {
+		isParentNodeExist, err := service.NodeRepository.CheckByID(ctx, tx, *parentId)
+		if err != nil {
+			return nil, err
+		}
+		if !isParentNodeExist {
+			return nil, fiber.NewError(fiber.StatusUnprocessableEntity, "Parent node is not found")
+		}
+		rootParentId = uuid.NullUUID{UUID: uuid.MustParse(*parentId), Valid: true}
+
+		parentClosures, err = service.NodeClosureRepository.FindByDescendant(ctx, tx, *parentId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Append Imported Roots After Existing Siblings
+	rootPosition, err := service.resolvePosition(ctx, tx, uuid.Nil, rootParentId, dto.NodePositionRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Build Closures From Parent Chains Inside The Import, on a copy so a retried transaction starts over
+	nodes = slices.Clone(nodes)
+	chains := map[uuid.UUID][]uuid.UUID{}
+	var closures []domain.NodeClosure
+	for i := range nodes {
+		node := &nodes[i]
+		chain := []uuid.UUID{node.ID}
+		if node.ParentID.Valid {
+			chain = append(chain, chains[node.ParentID.UUID]...)
+		} else {
+			node.ParentID = rootParentId
+			node.Position = rootPosition
+			rootPosition += positionGap
+		}
+		chains[node.ID] = chain
+
+		for depth, ancestor := range chain {
+			closures = append(closures, domain.NodeClosure{
+				Ancestor:   ancestor,
+				Descendant: node.ID,
+				Depth:      depth,
+			})
+		}
+		for _, parentClosure := range parentClosures {
+			closures = append(closures, domain.NodeClosure{
+				Ancestor:   parentClosure.Ancestor,
+				Descendant: node.ID,
+				Depth:      len(chain) + parentClosure.Depth,
+			})
+		}
+	}
+
+	// Save Nodes and Node Closures
+	err = service.NodeRepository.CreateBulk(ctx, tx, nodes)
+	if err != nil {
+		return nil, err
+	}
+	err = service.NodeClosureRepository.SaveBulk(ctx, tx, closures)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect Cached Reads Showing The Imported Roots
+	rootIds := []string{}
+	for _, id := range importRootIds(nodes) {
+		rootIds = append(rootIds, id.String())
+	}
+	return service.nodeCacheKeys(ctx, tx, rootIds)
+}
+
+// flattenImportItems assigns IDs and sibling positions to nested items and lists them parents first
+func flattenImportItems(items []dto.NodeImportItem, parentId uuid.NullUUID, createdAt sql.NullTime, nodes []domain.Node) []domain.Node {
+	for i, item := range items {
+		description := sql.NullString{Valid: false}
+		if item.Description != nil {
+			description = sql.NullString{String: *item.Description, Valid: true}
+		}
+		node := domain.Node{
+			ID:          uuid.New(),
+			ParentID:    parentId,
+			Title:       item.Title,
+			Type:        item.Type,
+			Description: description,
+			Position:    int64(i+1) * positionGap,
+			CreatedAt:   createdAt,
+		}
+		nodes = append(nodes, node)
+		nodes = flattenImportItems(item.Children, uuid.NullUUID{UUID: node.ID, Valid: true}, createdAt, nodes)
+	}
+
+	return nodes
+}
+
+// outlineImportItems converts parsed outline nodes into nested import items
+func outlineImportItems(nodes []outline.Node) []dto.NodeImportItem {
+	items := make([]dto.NodeImportItem, 0, len(nodes))
+	for _, node := range nodes {
+		items = append(items, dto.NodeImportItem{
+			Title:       node.Title,
+			Type:        node.Type,
+			Description: pkg.NullStringToPointer(node.Description),
+			Children:    outlineImportItems(node.Children),
+		})
+	}
+
+	return items
+}
+
+// importRootIds returns the IDs of the imported nodes that have no imported parent
+func importRootIds(nodes []domain.Node) []uuid.UUID {
+	imported := map[uuid.UUID]bool{}
+	ids := []uuid.UUID{}
+	for _, node := range nodes {
+		imported[node.ID] = true
+		if !node.ParentID.Valid || !imported[node.ParentID.UUID] {
+			ids = append(ids, node.ID)
+		}
+	}
+
+	return ids
 }